@@ -0,0 +1,253 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	ListingActive    = "active"
+	ListingSold      = "sold"
+	ListingExpired   = "expired"
+	ListingCancelled = "cancelled"
+)
+
+var ErrListingUnavailable = errors.New("listing is no longer available")
+
+type Listing struct {
+	ID        string    `bson:"id"`
+	Seller    string    `bson:"seller"` // Username
+	Item      Item      `bson:"item"`
+	Price     int       `bson:"price"`
+	CreatedAt time.Time `bson:"created_at"`
+	ExpiresAt time.Time `bson:"expires_at"`
+	Status    string    `bson:"status"`
+	Buyer     string    `bson:"buyer,omitempty"`
+}
+
+// ListingFilter narrows an auction search. Zero values mean "any".
+type ListingFilter struct {
+	Slot     string
+	Rarity   string
+	MinLevel int
+	MaxLevel int
+}
+
+func createAuctionIndexes(ctx context.Context, auctions *mongo.Collection) error {
+	_, err := auctions.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "expires_at", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "seller", Value: 1}, {Key: "status", Value: 1}},
+		},
+	})
+	return err
+}
+
+func (db *DB) CreateListing(listing *Listing) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := db.auctions.InsertOne(ctx, listing)
+	return err
+}
+
+func (db *DB) GetListing(id string) (*Listing, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var listing Listing
+	err := db.auctions.FindOne(ctx, bson.M{"id": id}).Decode(&listing)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrListingUnavailable
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &listing, nil
+}
+
+func (db *DB) CountActiveListings(seller string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return db.auctions.CountDocuments(ctx, bson.M{"seller": seller, "status": ListingActive})
+}
+
+// SearchListings returns active, unexpired listings matching the filter, cheapest first.
+func (db *DB) SearchListings(filter ListingFilter, limit int64) ([]*Listing, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := bson.M{
+		"status":     ListingActive,
+		"expires_at": bson.M{"$gt": time.Now()},
+	}
+	if filter.Slot != "" {
+		query["item.slot"] = filter.Slot
+	}
+	if filter.Rarity != "" {
+		query["item.rarity"] = filter.Rarity
+	}
+	level := bson.M{}
+	if filter.MinLevel > 0 {
+		level["$gte"] = filter.MinLevel
+	}
+	if filter.MaxLevel > 0 {
+		level["$lte"] = filter.MaxLevel
+	}
+	if len(level) > 0 {
+		query["item.level"] = level
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "price", Value: 1}}).SetLimit(limit)
+	cursor, err := db.auctions.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	listings := make([]*Listing, 0)
+	if err := cursor.All(ctx, &listings); err != nil {
+		return nil, err
+	}
+	return listings, nil
+}
+
+// ClaimListing atomically marks an active listing as sold to buyer. The price
+// must match what the buyer saw so a relisted item cannot be bought blind.
+func (db *DB) ClaimListing(id, buyer string, price int) (*Listing, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"id":         id,
+		"status":     ListingActive,
+		"price":      price,
+		"expires_at": bson.M{"$gt": time.Now()},
+	}
+	update := bson.M{"$set": bson.M{"status": ListingSold, "buyer": buyer}}
+
+	var listing Listing
+	err := db.auctions.FindOneAndUpdate(ctx, filter, update).Decode(&listing)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrListingUnavailable
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &listing, nil
+}
+
+// CancelListing withdraws an active listing owned by seller.
+func (db *DB) CancelListing(id, seller string) (*Listing, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"id": id, "seller": seller, "status": ListingActive}
+	update := bson.M{"$set": bson.M{"status": ListingCancelled}}
+
+	var listing Listing
+	err := db.auctions.FindOneAndUpdate(ctx, filter, update).Decode(&listing)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrListingUnavailable
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &listing, nil
+}
+
+// ExpireListings flips every active listing past its expiry to expired and
+// returns them so the items can be sent back to their sellers.
+func (db *DB) ExpireListings(now time.Time) ([]*Listing, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	query := bson.M{"status": ListingActive, "expires_at": bson.M{"$lte": now}}
+	cursor, err := db.auctions.Find(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	var candidates []*Listing
+	if err := cursor.All(ctx, &candidates); err != nil {
+		return nil, err
+	}
+
+	expired := make([]*Listing, 0, len(candidates))
+	for _, c := range candidates {
+		// Re-check status so a listing bought in the meantime is not returned too
+		filter := bson.M{"id": c.ID, "status": ListingActive}
+		update := bson.M{"$set": bson.M{"status": ListingExpired}}
+		var listing Listing
+		err := db.auctions.FindOneAndUpdate(ctx, filter, update).Decode(&listing)
+		if err == mongo.ErrNoDocuments {
+			continue
+		}
+		if err != nil {
+			return expired, err
+		}
+		expired = append(expired, &listing)
+	}
+	return expired, nil
+}
+
+// DeliverGold adds gold to a user's mailbox. It is claimed on next login
+// (or immediately if the user is online).
+func (db *DB) DeliverGold(username string, amount int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	update := bson.M{"$inc": bson.M{"pending_gold": amount}}
+	result, err := db.users.UpdateOne(ctx, bson.M{"username": username}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("user not found")
+	}
+	return nil
+}
+
+// DeliverItem adds an item to a user's mailbox.
+func (db *DB) DeliverItem(username string, item Item) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	update := bson.M{"$push": bson.M{"pending_items": item}}
+	result, err := db.users.UpdateOne(ctx, bson.M{"username": username}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("user not found")
+	}
+	return nil
+}
+
+// ClaimMailbox atomically empties a user's mailbox and returns its contents.
+func (db *DB) ClaimMailbox(username string) (int, []Item, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"username": username}
+	update := bson.M{"$set": bson.M{"pending_gold": 0, "pending_items": []Item{}}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.Before)
+
+	var user User
+	err := db.users.FindOneAndUpdate(ctx, filter, update, opts).Decode(&user)
+	if err != nil {
+		return 0, nil, err
+	}
+	return user.PendingGold, user.PendingItems, nil
+}
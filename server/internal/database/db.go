@@ -12,8 +12,9 @@ import (
 )
 
 type DB struct {
-	client *mongo.Client
-	users  *mongo.Collection
+	client   *mongo.Client
+	users    *mongo.Collection
+	auctions *mongo.Collection
 }
 
 type User struct {
@@ -22,6 +23,12 @@ type User struct {
 	PasswordHash string       `bson:"password_hash"`
 	CreatedAt    time.Time    `bson:"created_at"`
 	Characters   []*Character `bson:"characters"`
+	Stash        *Stash       `bson:"stash,omitempty"`   // Shared by all characters on the account
+	Profile      *Profile     `bson:"profile,omitempty"` // Account-wide achievements and lifetime stats
+
+	// Mailbox for deliveries made while offline (auction sales, expired listings)
+	PendingGold  int    `bson:"pending_gold"`
+	PendingItems []Item `bson:"pending_items"`
 }
 
 type Character struct {
@@ -36,6 +43,36 @@ type Character struct {
 	Stats     Stats           `bson:"stats"`
 	Inventory []Item          `bson:"inventory"`
 	Equipment map[string]Item `bson:"equipment"`
+	LootPity  int             `bson:"loot_pity"` // Drops since the last Legendary
+	Materials map[string]int  `bson:"materials,omitempty"`
+
+	// Progression
+	StatPoints     int            `bson:"stat_points"`
+	TalentPoints   int            `bson:"talent_points"`
+	Talents        map[string]int `bson:"talents,omitempty"`
+	AllocatedStats Stats          `bson:"allocated_stats"` // Player-spent points, refunded on respec
+
+	// Quests
+	Quests          []QuestProgress `bson:"quests,omitempty"`
+	CompletedQuests []string        `bson:"completed_quests,omitempty"`
+}
+
+type QuestProgress struct {
+	QuestID  string `bson:"quest_id"`
+	Progress []int  `bson:"progress"`
+	Complete bool   `bson:"complete"`
+}
+
+type Stash struct {
+	Tabs [][]Item `bson:"tabs"`
+}
+
+type Profile struct {
+	Achievements map[string]int64 `bson:"achievements"` // ID -> unlock time (unix ms)
+	Kills        map[string]int   `bson:"kills"`        // By enemy subtype
+	Deaths       int              `bson:"deaths"`
+	GoldEarned   int              `bson:"gold_earned"`
+	DamageDealt  int              `bson:"damage_dealt"`
 }
 
 type Stats struct {
@@ -57,6 +94,28 @@ type Item struct {
 	Value       int            `bson:"value"`
 	Icon        string         `bson:"icon"`
 	Description string         `bson:"description"`
+	Base        string         `bson:"base,omitempty"`
+	Implicit    map[string]int `bson:"implicit,omitempty"`
+	Affixes     []ItemAffix    `bson:"affixes,omitempty"`
+	UniqueID    string         `bson:"unique_id,omitempty"`
+	SetID       string         `bson:"set_id,omitempty"`
+	Sockets     int            `bson:"sockets,omitempty"`
+	Gems        []Item         `bson:"gems,omitempty"`
+	GemType     string         `bson:"gem_type,omitempty"`
+	GemTier     int            `bson:"gem_tier,omitempty"`
+
+	Durability    int `bson:"durability,omitempty"`
+	MaxDurability int `bson:"max_durability,omitempty"`
+}
+
+type ItemAffix struct {
+	ID    string `bson:"id"`
+	Kind  string `bson:"kind"` // prefix, suffix
+	Stat  string `bson:"stat"`
+	Tier  int    `bson:"tier"`
+	Value int    `bson:"value"`
+
+	Enchanted bool `bson:"enchanted,omitempty"`
 }
 
 func New(uri string) (*DB, error) {
@@ -85,9 +144,15 @@ func New(uri string) (*DB, error) {
 		return nil, err
 	}
 
+	auctions := db.Collection("auctions")
+	if err := createAuctionIndexes(ctx, auctions); err != nil {
+		return nil, err
+	}
+
 	return &DB{
-		client: client,
-		users:  users,
+		client:   client,
+		users:    users,
+		auctions: auctions,
 	}, nil
 }
 
@@ -217,3 +282,37 @@ func (db *DB) SaveCharacter(username string, char *Character) error {
 	_, err := db.users.UpdateOne(ctx, filter, update)
 	return err
 }
+
+func (db *DB) SaveStash(username string, stash *Stash) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"username": username}
+	update := bson.M{"$set": bson.M{"stash": stash}}
+
+	result, err := db.users.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("user not found")
+	}
+	return nil
+}
+
+func (db *DB) SaveProfile(username string, profile *Profile) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"username": username}
+	update := bson.M{"$set": bson.M{"profile": profile}}
+
+	result, err := db.users.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("user not found")
+	}
+	return nil
+}
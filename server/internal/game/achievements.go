@@ -0,0 +1,172 @@
+package game
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	AchievementFirstElite    = "first_elite"
+	AchievementLevel50       = "level_50"
+	AchievementRestoreRegion = "restore_region"
+	AchievementLegendary     = "legendary_loot"
+)
+
+type AchievementDef struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+var Achievements = []AchievementDef{
+	{ID: AchievementFirstElite, Name: "Giant Slayer", Description: "Defeat an elite enemy"},
+	{ID: AchievementLevel50, Name: "Veteran of Aethelgard", Description: "Reach level 50"},
+	{ID: AchievementRestoreRegion, Name: "Restorer", Description: "Be in a realm when it is restored"},
+	{ID: AchievementLegendary, Name: "Legend in Hand", Description: "Loot a Legendary item"},
+}
+
+func GetAchievement(id string) *AchievementDef {
+	for i := range Achievements {
+		if Achievements[i].ID == id {
+			return &Achievements[i]
+		}
+	}
+	return nil
+}
+
+// LifetimeStats are account-wide counters across all characters.
+type LifetimeStats struct {
+	Kills       map[string]int `json:"kills"` // By enemy subtype
+	Deaths      int            `json:"deaths"`
+	GoldEarned  int            `json:"goldEarned"`
+	DamageDealt int            `json:"damageDealt"`
+}
+
+// Profile is the account-wide achievement record. Like the stash it is shared
+// by every character on the account.
+type Profile struct {
+	Achievements map[string]int64 `json:"achievements"` // ID -> unlock time (unix ms)
+	Stats        LifetimeStats    `json:"stats"`
+}
+
+func NewProfile() *Profile {
+	return &Profile{
+		Achievements: make(map[string]int64),
+		Stats:        LifetimeStats{Kills: make(map[string]int)},
+	}
+}
+
+func (p *Profile) Copy() *Profile {
+	c := &Profile{
+		Achievements: make(map[string]int64, len(p.Achievements)),
+		Stats:        p.Stats,
+	}
+	for k, v := range p.Achievements {
+		c.Achievements[k] = v
+	}
+	c.Stats.Kills = make(map[string]int, len(p.Stats.Kills))
+	for k, v := range p.Stats.Kills {
+		c.Stats.Kills[k] = v
+	}
+	return c
+}
+
+// unlockAchievement records an achievement once and announces it. Caller must hold the lock.
+func (w *World) unlockAchievement(player *Entity, id string) {
+	if player.Profile == nil {
+		return
+	}
+	if _, done := player.Profile.Achievements[id]; done {
+		return
+	}
+	def := GetAchievement(id)
+	if def == nil {
+		return
+	}
+	if player.Profile.Achievements == nil {
+		player.Profile.Achievements = make(map[string]int64)
+	}
+	player.Profile.Achievements[id] = time.Now().UnixMilli()
+	if w.OnEvent != nil {
+		w.OnEvent("achievement", fmt.Sprintf("%s earned the achievement [%s]!", player.Name, def.Name))
+	}
+}
+
+// recordKill counts a kill towards lifetime stats. Caller must hold the lock.
+func (w *World) recordKill(player, target *Entity) {
+	if player.Profile == nil {
+		return
+	}
+	if player.Profile.Stats.Kills == nil {
+		player.Profile.Stats.Kills = make(map[string]int)
+	}
+	player.Profile.Stats.Kills[target.SubType]++
+	if target.IsElite() {
+		w.unlockAchievement(player, AchievementFirstElite)
+	}
+}
+
+// recordLevel checks level achievements after XP gains. Caller must hold the lock.
+func (w *World) recordLevel(player *Entity) {
+	if player.Level >= 50 {
+		w.unlockAchievement(player, AchievementLevel50)
+	}
+}
+
+// recordLoot checks loot achievements after a pickup. Caller must hold the lock.
+func (w *World) recordLoot(player *Entity, item Item) {
+	if item.Rarity == RarityLegendary {
+		w.unlockAchievement(player, AchievementLegendary)
+	}
+}
+
+// recordDeath counts a player death.
+func (e *Entity) recordDeath() {
+	if e.Profile != nil {
+		e.Profile.Stats.Deaths++
+	}
+}
+
+// recordDamage counts damage a player dealt to enemies. Nil-safe for ownerless projectiles.
+func (e *Entity) recordDamage(amount int) {
+	if e != nil && e.Profile != nil && amount > 0 {
+		e.Profile.Stats.DamageDealt += amount
+	}
+}
+
+// earnGold adds gold from loot, quests or sales and counts it as earned.
+func (e *Entity) earnGold(amount int) {
+	e.Gold += amount
+	if e.Profile != nil && amount > 0 {
+		e.Profile.Stats.GoldEarned += amount
+	}
+}
+
+type AchievementStatus struct {
+	AchievementDef
+	Unlocked   bool  `json:"unlocked"`
+	UnlockedAt int64 `json:"unlockedAt,omitempty"`
+}
+
+// ProfileView is sent to the client's profile screen
+type ProfileView struct {
+	Achievements []AchievementStatus `json:"achievements"`
+	Stats        LifetimeStats       `json:"stats"`
+}
+
+func (w *World) GetProfile(playerID string) (*ProfileView, bool) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	player, ok := w.Entities[playerID]
+	if !ok || player.Profile == nil {
+		return nil, false
+	}
+	p := player.Profile.Copy()
+	view := &ProfileView{Stats: p.Stats}
+	for _, def := range Achievements {
+		at, done := p.Achievements[def.ID]
+		view.Achievements = append(view.Achievements, AchievementStatus{AchievementDef: def, Unlocked: done, UnlockedAt: at})
+	}
+	return view, true
+}
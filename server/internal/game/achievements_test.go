@@ -0,0 +1,97 @@
+package game
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func newProfileTestPlayer(w *World) *Entity {
+	player := &Entity{
+		ID:            "p1",
+		Name:          "Hero",
+		Type:          TypePlayer,
+		SubType:       "Fighter",
+		Level:         1,
+		MaxExperience: 100,
+		X:             100,
+		Z:             0,
+		BaseStats:     Stats{Strength: 10, Vitality: 10},
+		Profile:       NewProfile(),
+	}
+	player.RecalculateStats()
+	w.Entities[player.ID] = player
+	return player
+}
+
+func TestLifetimeStatsFromCombat(t *testing.T) {
+	w := NewWorld()
+	player := newProfileTestPlayer(w)
+
+	enemy := &Entity{ID: "Skeleton-test", Type: TypeEnemy, SubType: "Skeleton", Level: 1, Health: 1, MaxHealth: 1, X: 101, Z: 0}
+	w.Entities[enemy.ID] = enemy
+
+	combatRoll = func() float64 { return 0.99 }
+	defer func() { combatRoll = rand.Float64 }()
+
+	hit, ok := w.PerformAttack(player.ID, enemy.ID)
+	if !ok {
+		t.Fatal("Attack failed")
+	}
+	stats := player.Profile.Stats
+	if stats.Kills["Skeleton"] != 1 {
+		t.Errorf("Kills = %v", stats.Kills)
+	}
+	if stats.DamageDealt != hit.Damage {
+		t.Errorf("DamageDealt = %d, want %d", stats.DamageDealt, hit.Damage)
+	}
+	if stats.GoldEarned != player.Gold {
+		t.Errorf("GoldEarned = %d, gold = %d", stats.GoldEarned, player.Gold)
+	}
+	if _, ok := player.Profile.Achievements[AchievementFirstElite]; ok {
+		t.Error("Normal kill unlocked the elite achievement")
+	}
+}
+
+func TestAchievementsUnlockOnceAndAnnounce(t *testing.T) {
+	w := NewWorld()
+	player := newProfileTestPlayer(w)
+
+	var announced []string
+	w.OnEvent = func(eventType string, data interface{}) {
+		if eventType == "achievement" {
+			announced = append(announced, data.(string))
+		}
+	}
+
+	elite := &Entity{ID: "elite-test", Type: TypeEnemy, SubType: "DemonOrc", Level: 5}
+	w.recordKill(player, elite)
+	w.recordKill(player, elite)
+	if _, ok := player.Profile.Achievements[AchievementFirstElite]; !ok {
+		t.Fatal("Elite kill not recorded")
+	}
+	if len(announced) != 1 || !strings.Contains(announced[0], "Hero") {
+		t.Fatalf("Announcements = %v", announced)
+	}
+
+	w.recordLoot(player, Item{Rarity: RarityLegendary})
+	player.Level = 50
+	w.recordLevel(player)
+
+	for i := 0; i < RegionRestoreKills; i++ {
+		w.recordRegionKill(&Entity{X: 100, Z: 0})
+	}
+
+	view, ok := w.GetProfile(player.ID)
+	if !ok {
+		t.Fatal("GetProfile failed")
+	}
+	for _, a := range view.Achievements {
+		if !a.Unlocked || a.UnlockedAt == 0 {
+			t.Errorf("%s not unlocked", a.ID)
+		}
+	}
+	if len(announced) != len(Achievements) {
+		t.Errorf("Expected %d announcements, got %d", len(Achievements), len(announced))
+	}
+}
@@ -0,0 +1,371 @@
+package game
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+type AffixKind string
+
+const (
+	AffixPrefix AffixKind = "prefix"
+	AffixSuffix AffixKind = "suffix"
+)
+
+// Stat keys used in Item.Stats beyond the five primary stats.
+// Percent stats are stored as whole percentage points.
+const (
+	StatDamage      = "damage"
+	StatDefense     = "defense"
+	StatCritChance  = "critChance"  // %
+	StatLifeOnHit   = "lifeOnHit"   // Flat HP per hit
+	StatAttackSpeed = "attackSpeed" // %
+	StatMoveSpeed   = "moveSpeed"   // %
+	StatFireResist  = "fireResist"  // %
+	StatWaterResist = "waterResist" // %
+	StatEarthResist = "earthResist" // %
+	StatAirResist   = "airResist"   // %
+	StatMagicFind   = "magicFind"   // % better Rare/Legendary odds
+)
+
+// Max affixes of each kind on one item
+const MaxAffixesPerKind = 3
+
+type AffixTier struct {
+	Tier     int // 1 is the weakest
+	MinLevel int // Minimum item level to roll this tier
+	Min      int
+	Max      int
+	Weight   int
+}
+
+type AffixDef struct {
+	ID      string
+	Name    string // Prefix word ("Strong") or suffix phrase ("of the Bear")
+	Kind    AffixKind
+	Stat    string
+	Label   string   // Human readable stat name for descriptions
+	Percent bool     // Display as a percentage
+	Scales  bool     // Value is multiplied by the rarity multiplier
+	Slots   []string // Empty means any slot
+	Weight  int
+	Tiers   []AffixTier
+}
+
+// ItemAffix is one rolled affix on an item
+type ItemAffix struct {
+	ID    string    `json:"id" bson:"id"`
+	Kind  AffixKind `json:"kind" bson:"kind"`
+	Stat  string    `json:"stat" bson:"stat"`
+	Tier  int       `json:"tier" bson:"tier"`
+	Value int       `json:"value" bson:"value"`
+
+	Enchanted bool `json:"enchanted,omitempty" bson:"enchanted"` // Rerolled by enchanting
+}
+
+var weaponSlots = []string{"mainHand"}
+var handSlots = []string{"mainHand", "offHand"}
+var armorSlots = []string{"head", "chest", "legs", "feet", "offHand"}
+
+// Tier table shared by the five primary stats
+var attributeTiers = []AffixTier{
+	{Tier: 1, MinLevel: 1, Min: 1, Max: 3, Weight: 100},
+	{Tier: 2, MinLevel: 5, Min: 4, Max: 8, Weight: 80},
+	{Tier: 3, MinLevel: 10, Min: 9, Max: 15, Weight: 60},
+	{Tier: 4, MinLevel: 15, Min: 16, Max: 24, Weight: 40},
+	{Tier: 5, MinLevel: 20, Min: 25, Max: 35, Weight: 20},
+	{Tier: 6, MinLevel: 30, Min: 36, Max: 50, Weight: 10},
+}
+
+var resistTiers = []AffixTier{
+	{Tier: 1, MinLevel: 1, Min: 3, Max: 6, Weight: 100},
+	{Tier: 2, MinLevel: 8, Min: 7, Max: 12, Weight: 70},
+	{Tier: 3, MinLevel: 15, Min: 13, Max: 20, Weight: 40},
+	{Tier: 4, MinLevel: 22, Min: 21, Max: 30, Weight: 15},
+}
+
+// Affixes is the affix database used by item generation
+var Affixes = []AffixDef{
+	// Prefixes - Primary stats
+	{ID: "str_prefix", Name: "Strong", Kind: AffixPrefix, Stat: "strength", Label: "Strength", Scales: true, Weight: 100, Tiers: attributeTiers},
+	{ID: "dex_prefix", Name: "Agile", Kind: AffixPrefix, Stat: "dexterity", Label: "Dexterity", Scales: true, Weight: 100, Tiers: attributeTiers},
+	{ID: "int_prefix", Name: "Brilliant", Kind: AffixPrefix, Stat: "intelligence", Label: "Intelligence", Scales: true, Weight: 100, Tiers: attributeTiers},
+	{ID: "wis_prefix", Name: "Wise", Kind: AffixPrefix, Stat: "wisdom", Label: "Wisdom", Scales: true, Weight: 100, Tiers: attributeTiers},
+	{ID: "vit_prefix", Name: "Hearty", Kind: AffixPrefix, Stat: "vitality", Label: "Vitality", Scales: true, Weight: 100, Tiers: attributeTiers},
+
+	// Prefixes - Combat
+	{ID: "damage", Name: "Brutal", Kind: AffixPrefix, Stat: StatDamage, Label: "Damage", Scales: true, Slots: weaponSlots, Weight: 60, Tiers: []AffixTier{
+		{Tier: 1, MinLevel: 1, Min: 2, Max: 4, Weight: 100},
+		{Tier: 2, MinLevel: 8, Min: 5, Max: 10, Weight: 60},
+		{Tier: 3, MinLevel: 16, Min: 11, Max: 18, Weight: 30},
+	}},
+	{ID: "defense", Name: "Sturdy", Kind: AffixPrefix, Stat: StatDefense, Label: "Defense", Scales: true, Slots: armorSlots, Weight: 60, Tiers: []AffixTier{
+		{Tier: 1, MinLevel: 1, Min: 1, Max: 3, Weight: 100},
+		{Tier: 2, MinLevel: 8, Min: 4, Max: 7, Weight: 60},
+		{Tier: 3, MinLevel: 16, Min: 8, Max: 12, Weight: 30},
+	}},
+	{ID: "crit", Name: "Keen", Kind: AffixPrefix, Stat: StatCritChance, Label: "Critical Strike Chance", Percent: true, Slots: []string{"mainHand", "head"}, Weight: 40, Tiers: []AffixTier{
+		{Tier: 1, MinLevel: 1, Min: 1, Max: 2, Weight: 100},
+		{Tier: 2, MinLevel: 8, Min: 3, Max: 4, Weight: 60},
+		{Tier: 3, MinLevel: 15, Min: 5, Max: 7, Weight: 25},
+	}},
+	{ID: "attack_speed", Name: "Swift", Kind: AffixPrefix, Stat: StatAttackSpeed, Label: "Attack Speed", Percent: true, Slots: handSlots, Weight: 40, Tiers: []AffixTier{
+		{Tier: 1, MinLevel: 5, Min: 3, Max: 5, Weight: 100},
+		{Tier: 2, MinLevel: 12, Min: 6, Max: 9, Weight: 60},
+		{Tier: 3, MinLevel: 20, Min: 10, Max: 14, Weight: 25},
+	}},
+	{ID: "move_speed", Name: "Fleet", Kind: AffixPrefix, Stat: StatMoveSpeed, Label: "Movement Speed", Percent: true, Slots: []string{"feet"}, Weight: 60, Tiers: []AffixTier{
+		{Tier: 1, MinLevel: 1, Min: 3, Max: 5, Weight: 100},
+		{Tier: 2, MinLevel: 10, Min: 6, Max: 9, Weight: 60},
+		{Tier: 3, MinLevel: 20, Min: 10, Max: 14, Weight: 25},
+	}},
+
+	// Suffixes - Primary stats
+	{ID: "str_suffix", Name: "of the Bear", Kind: AffixSuffix, Stat: "strength", Label: "Strength", Scales: true, Weight: 100, Tiers: attributeTiers},
+	{ID: "dex_suffix", Name: "of the Tiger", Kind: AffixSuffix, Stat: "dexterity", Label: "Dexterity", Scales: true, Weight: 100, Tiers: attributeTiers},
+	{ID: "int_suffix", Name: "of the Owl", Kind: AffixSuffix, Stat: "intelligence", Label: "Intelligence", Scales: true, Weight: 100, Tiers: attributeTiers},
+	{ID: "wis_suffix", Name: "of the Eagle", Kind: AffixSuffix, Stat: "wisdom", Label: "Wisdom", Scales: true, Weight: 100, Tiers: attributeTiers},
+	{ID: "vit_suffix", Name: "of the Whale", Kind: AffixSuffix, Stat: "vitality", Label: "Vitality", Scales: true, Weight: 100, Tiers: attributeTiers},
+
+	// Suffixes - Utility
+	{ID: "life_on_hit", Name: "of the Leech", Kind: AffixSuffix, Stat: StatLifeOnHit, Label: "Life on Hit", Slots: handSlots, Weight: 40, Tiers: []AffixTier{
+		{Tier: 1, MinLevel: 3, Min: 1, Max: 3, Weight: 100},
+		{Tier: 2, MinLevel: 10, Min: 4, Max: 8, Weight: 60},
+		{Tier: 3, MinLevel: 18, Min: 9, Max: 15, Weight: 25},
+	}},
+	{ID: "magic_find", Name: "of Fortune", Kind: AffixSuffix, Stat: StatMagicFind, Label: "Magic Find", Percent: true, Slots: []string{"head", "offHand", "feet"}, Weight: 30, Tiers: []AffixTier{
+		{Tier: 1, MinLevel: 5, Min: 5, Max: 10, Weight: 100},
+		{Tier: 2, MinLevel: 12, Min: 11, Max: 20, Weight: 60},
+		{Tier: 3, MinLevel: 20, Min: 21, Max: 35, Weight: 25},
+	}},
+
+	// Suffixes - Elemental resistances (one per realm)
+	{ID: "fire_resist", Name: "of the Ember", Kind: AffixSuffix, Stat: StatFireResist, Label: "Fire Resistance", Percent: true, Slots: armorSlots, Weight: 50, Tiers: resistTiers},
+	{ID: "water_resist", Name: "of the Tide", Kind: AffixSuffix, Stat: StatWaterResist, Label: "Water Resistance", Percent: true, Slots: armorSlots, Weight: 50, Tiers: resistTiers},
+	{ID: "earth_resist", Name: "of the Stone", Kind: AffixSuffix, Stat: StatEarthResist, Label: "Earth Resistance", Percent: true, Slots: armorSlots, Weight: 50, Tiers: resistTiers},
+	{ID: "air_resist", Name: "of the Gale", Kind: AffixSuffix, Stat: StatAirResist, Label: "Air Resistance", Percent: true, Slots: armorSlots, Weight: 50, Tiers: resistTiers},
+}
+
+var affixByID = func() map[string]*AffixDef {
+	m := make(map[string]*AffixDef, len(Affixes))
+	for i := range Affixes {
+		m[Affixes[i].ID] = &Affixes[i]
+	}
+	return m
+}()
+
+// Rarity multipliers applied to the base stat and to scaling affixes
+var RarityMultiplier = map[ItemRarity]float64{
+	RarityCommon:    1.0,
+	RarityUncommon:  1.5,
+	RarityRare:      3.0,
+	RarityLegendary: 5.0,
+}
+
+// Extra tier weight per tier step, so better rarities lean towards higher tiers
+var rarityTierBias = map[ItemRarity]int{
+	RarityRare:      1,
+	RarityLegendary: 2,
+}
+
+func GetAffix(id string) *AffixDef {
+	return affixByID[id]
+}
+
+func (a *AffixDef) allowsSlot(slot string) bool {
+	if len(a.Slots) == 0 {
+		return true
+	}
+	for _, s := range a.Slots {
+		if s == slot {
+			return true
+		}
+	}
+	return false
+}
+
+// eligibleAffixes lists affixes of a kind that can roll on a slot, minus any excluded IDs.
+func eligibleAffixes(kind AffixKind, slot string, level int, exclude map[string]bool) []*AffixDef {
+	var out []*AffixDef
+	for i := range Affixes {
+		a := &Affixes[i]
+		if a.Kind != kind || exclude[a.ID] || !a.allowsSlot(slot) {
+			continue
+		}
+		if len(a.Tiers) == 0 || a.Tiers[0].MinLevel > level {
+			continue
+		}
+		out = append(out, a)
+	}
+	return out
+}
+
+func pickWeightedAffix(candidates []*AffixDef) *AffixDef {
+	total := 0
+	for _, a := range candidates {
+		total += a.Weight
+	}
+	if total <= 0 {
+		return nil
+	}
+	roll := rand.Intn(total)
+	for _, a := range candidates {
+		roll -= a.Weight
+		if roll < 0 {
+			return a
+		}
+	}
+	return candidates[len(candidates)-1]
+}
+
+// rollAffixValue picks a tier allowed at this item level and rolls a value in it.
+func rollAffixValue(def *AffixDef, level int, rarity ItemRarity) ItemAffix {
+	bias := rarityTierBias[rarity]
+
+	total := 0
+	var eligible []AffixTier
+	for _, t := range def.Tiers {
+		if t.MinLevel <= level {
+			eligible = append(eligible, t)
+			total += t.Weight * (1 + bias*(t.Tier-1))
+		}
+	}
+
+	tier := eligible[0]
+	roll := rand.Intn(total)
+	for _, t := range eligible {
+		roll -= t.Weight * (1 + bias*(t.Tier-1))
+		if roll < 0 {
+			tier = t
+			break
+		}
+	}
+
+	value := tier.Min + rand.Intn(tier.Max-tier.Min+1)
+	if def.Scales {
+		value = int(float64(value) * RarityMultiplier[rarity])
+	}
+
+	return ItemAffix{
+		ID:    def.ID,
+		Kind:  def.Kind,
+		Stat:  def.Stat,
+		Tier:  tier.Tier,
+		Value: value,
+	}
+}
+
+// rollAffixes rolls count affixes for an item, alternating prefixes and suffixes.
+func rollAffixes(slot string, level int, rarity ItemRarity, count int) []ItemAffix {
+	affixes := make([]ItemAffix, 0, count)
+	used := make(map[string]bool)
+	perKind := map[AffixKind]int{}
+
+	kind := AffixPrefix
+	if rand.Intn(2) == 0 {
+		kind = AffixSuffix
+	}
+
+	for len(affixes) < count {
+		if perKind[kind] >= MaxAffixesPerKind {
+			kind = otherKind(kind)
+			if perKind[kind] >= MaxAffixesPerKind {
+				break
+			}
+		}
+
+		def := pickWeightedAffix(eligibleAffixes(kind, slot, level, used))
+		if def == nil {
+			// Nothing left of this kind; try the other one once
+			kind = otherKind(kind)
+			def = pickWeightedAffix(eligibleAffixes(kind, slot, level, used))
+			if def == nil {
+				break
+			}
+		}
+
+		affixes = append(affixes, rollAffixValue(def, level, rarity))
+		used[def.ID] = true
+		perKind[kind]++
+		kind = otherKind(kind)
+	}
+	return affixes
+}
+
+func otherKind(kind AffixKind) AffixKind {
+	if kind == AffixPrefix {
+		return AffixSuffix
+	}
+	return AffixPrefix
+}
+
+// RebuildStats recomputes Stats from the implicit base stat plus rolled affixes.
+// Items saved before affixes existed have no Implicit and are left untouched.
+func (item *Item) RebuildStats() {
+	if item.Implicit == nil {
+		return
+	}
+	stats := make(map[string]int)
+	for k, v := range item.Implicit {
+		stats[k] += v
+	}
+	for _, a := range item.Affixes {
+		stats[a.Stat] += a.Value
+	}
+	for _, gem := range item.Gems {
+		for k, v := range gem.Stats {
+			stats[k] += v
+		}
+	}
+	item.Stats = stats
+}
+
+// RebuildName names the item after its highest tier prefix and suffix.
+func (item *Item) RebuildName() {
+	if item.Base == "" {
+		return
+	}
+	var prefix, suffix *ItemAffix
+	for i := range item.Affixes {
+		a := &item.Affixes[i]
+		if a.Kind == AffixPrefix && (prefix == nil || a.Tier > prefix.Tier) {
+			prefix = a
+		}
+		if a.Kind == AffixSuffix && (suffix == nil || a.Tier > suffix.Tier) {
+			suffix = a
+		}
+	}
+
+	name := item.Base
+	if prefix != nil {
+		if def := GetAffix(prefix.ID); def != nil {
+			name = fmt.Sprintf("%s %s", def.Name, name)
+		}
+	}
+	if suffix != nil {
+		if def := GetAffix(suffix.ID); def != nil {
+			name = fmt.Sprintf("%s %s", name, def.Name)
+		}
+	} else if item.Rarity == RarityLegendary {
+		name = fmt.Sprintf("%s of Legends", name)
+	}
+	item.Name = name
+}
+
+// RebuildDescription lists the rolled affixes, one per line.
+func (item *Item) RebuildDescription() {
+	var lines []string
+	for _, a := range item.Affixes {
+		def := GetAffix(a.ID)
+		if def == nil {
+			continue
+		}
+		if def.Percent {
+			lines = append(lines, fmt.Sprintf("+%d%% %s", a.Value, def.Label))
+		} else {
+			lines = append(lines, fmt.Sprintf("+%d %s", a.Value, def.Label))
+		}
+	}
+	item.Description = strings.Join(lines, "\n")
+}
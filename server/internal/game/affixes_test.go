@@ -0,0 +1,70 @@
+package game
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCreateItemAffixes(t *testing.T) {
+	boots := BaseItem{"Leather Boots", ItemArmor, "feet", "defense", 2, ""}
+
+	for i := 0; i < 200; i++ {
+		item := createItem(boots, RarityRare, 3.0, 2, 12)
+
+		if len(item.Affixes) != 2 {
+			t.Fatalf("Rare item rolled %d affixes, want 2", len(item.Affixes))
+		}
+		seen := make(map[string]bool)
+		sum := make(map[string]int)
+		for k, v := range item.Implicit {
+			sum[k] += v
+		}
+		for _, a := range item.Affixes {
+			def := GetAffix(a.ID)
+			if def == nil {
+				t.Fatalf("Unknown affix %q", a.ID)
+			}
+			if !def.allowsSlot("feet") {
+				t.Errorf("Affix %q rolled on disallowed slot feet", a.ID)
+			}
+			if seen[a.ID] {
+				t.Errorf("Affix %q rolled twice", a.ID)
+			}
+			seen[a.ID] = true
+			for _, tier := range def.Tiers {
+				if tier.Tier == a.Tier && tier.MinLevel > item.Level {
+					t.Errorf("Affix %q tier %d requires level %d, item is %d", a.ID, a.Tier, tier.MinLevel, item.Level)
+				}
+			}
+			sum[a.Stat] += a.Value
+		}
+		for k, v := range sum {
+			if item.Stats[k] != v {
+				t.Errorf("Stats[%q] = %d, want %d", k, item.Stats[k], v)
+			}
+		}
+	}
+}
+
+func TestRecalculateStatsAffixes(t *testing.T) {
+	e := &Entity{Level: 1, BaseStats: Stats{Strength: 10, Dexterity: 10, Intelligence: 10, Wisdom: 10, Vitality: 10}}
+	e.RecalculateStats()
+	baseSpeed := e.Speed
+	baseCrit := e.CritChance // From Dexterity
+
+	e.Equipment = map[string]Item{
+		"feet": {Stats: map[string]int{StatMoveSpeed: 10, StatFireResist: 20}},
+		"head": {Stats: map[string]int{StatCritChance: 5}},
+	}
+	e.RecalculateStats()
+
+	if e.Speed <= baseSpeed {
+		t.Errorf("Movement speed affix not applied: %f <= %f", e.Speed, baseSpeed)
+	}
+	if e.Resistances.Fire != 20 {
+		t.Errorf("Fire resistance = %d, want 20", e.Resistances.Fire)
+	}
+	if math.Abs(e.CritChance-baseCrit-0.05) > 1e-9 {
+		t.Errorf("CritChance = %f, want %f", e.CritChance, baseCrit+0.05)
+	}
+}
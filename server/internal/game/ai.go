@@ -0,0 +1,390 @@
+package game
+
+import (
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// AIKind names an enemy behaviour; subtypes pick one in their EnemyProfile
+type AIKind string
+
+const (
+	AIChase  AIKind = "chase"  // Run at the target and attack in range
+	AISwarm  AIKind = "swarm"  // Surround the target and bring the pack
+	AIKite   AIKind = "kite"   // Keep distance and shoot
+	AICharge AIKind = "charge" // Rush in from mid range
+	AIGuard  AIKind = "guard"  // Hold a post and never stray from it
+)
+
+// AIState is the high-level state shared by every behaviour
+type AIState string
+
+const (
+	AIIdle   AIState = "idle"   // Roaming or holding a post
+	AIEngage AIState = "engage" // Fighting a target
+	AIFlee   AIState = "flee"   // Running from a target at low health
+)
+
+const (
+	SightRange   = 45.0
+	RoamRadius   = 10.0
+	HelpRadius   = 20.0 // Allies this close answer a call for help
+	HelpThreat   = 1    // Threat given to allies; enough to pick the caller's target
+	FleeDuration = 4 * time.Second
+
+	KiteDistance = 8.0 // Kiters back off from anything closer
+
+	ChargeMinRange = 6.0
+	ChargeMaxRange = 20.0
+	ChargeSpeed    = 3.0 // Multiplier on movement speed while charging
+	ChargeCooldown = 6 * time.Second
+
+	GuardRadius = 15.0 // Guards only fight within this distance of their post
+)
+
+// aiTick is what a behaviour knows about the current tick
+type aiTick struct {
+	Now     time.Time
+	DT      float64
+	Target  *Entity
+	Dist    float64 // To Target
+	Visible bool    // Line of sight to Target
+	Profile EnemyProfile
+}
+
+// inReach is true when the target can be attacked from where the enemy stands.
+func (t *aiTick) inReach() bool {
+	return t.Visible && t.Dist <= t.Profile.Range
+}
+
+// Behavior drives an enemy each tick. Flee and call-for-help are handled for
+// every behaviour by updateEnemyAI.
+type Behavior interface {
+	// Accepts reports whether the enemy will fight the target it can see
+	Accepts(e *Entity, t *aiTick) bool
+	// Engage moves and attacks while fighting
+	Engage(w *World, e *Entity, t *aiTick)
+	// Idle runs with no target
+	Idle(w *World, e *Entity, t *aiTick)
+}
+
+var Behaviors = map[AIKind]Behavior{
+	AIChase:  chaseBehavior{},
+	AISwarm:  swarmBehavior{},
+	AIKite:   kiteBehavior{},
+	AICharge: chargeBehavior{},
+	AIGuard:  guardBehavior{},
+}
+
+func behaviorFor(kind AIKind) Behavior {
+	if b, ok := Behaviors[kind]; ok {
+		return b
+	}
+	return Behaviors[AIChase]
+}
+
+// now is the world clock (replaced in tests).
+func (w *World) now() time.Time {
+	if w.Clock != nil {
+		return w.Clock()
+	}
+	return time.Now()
+}
+
+// updateEnemyAI runs one tick of an enemy's AI. Caller must hold the lock.
+func (w *World) updateEnemyAI(e *Entity, players []*Entity, dt float64) {
+	profile := GetEnemyProfile(e.SubType)
+	t := &aiTick{Now: w.now(), DT: dt, Profile: profile}
+	t.Target, t.Dist = w.pickTarget(e, players)
+	if t.Target != nil {
+		t.Visible = w.hasLineOfSight(e, t.Target)
+	}
+	behavior := behaviorFor(profile.AI)
+	if e.isStunned(t.Now) {
+		return
+	}
+
+	if e.AIState == AIFlee {
+		if t.Target != nil && t.Now.Before(e.FleeUntil) {
+			w.fleeFrom(e, t.Target, dt)
+			return
+		}
+		e.AIState = AIIdle
+	}
+
+	if t.Target == nil || t.Dist > SightRange || !behavior.Accepts(e, t) {
+		e.AIState = AIIdle
+		e.AITarget = ""
+		e.IsCharging = false
+		e.Path = nil
+		behavior.Idle(w, e, t)
+		return
+	}
+
+	if e.shouldFlee(profile) {
+		e.AIState = AIFlee
+		e.FleeUntil = t.Now.Add(FleeDuration)
+		e.Fled = true
+		e.IsCharging = false
+		w.callForHelp(e, t.Target)
+		w.fleeFrom(e, t.Target, dt)
+		return
+	}
+
+	e.AIState = AIEngage
+	behavior.Engage(w, e, t)
+	e.AITarget = t.Target.ID
+}
+
+// pickTarget finds the nearest player outside the safe zone, preferring
+// whoever has built the most threat within sight. New targets must be in line
+// of sight; players already fought or hated are tracked around terrain.
+// Caller must hold the lock.
+func (w *World) pickTarget(e *Entity, players []*Entity) (*Entity, float64) {
+	var target, hated *Entity
+	minDist, hatedDist, maxThreat := 1000.0, 0.0, 0
+	for _, p := range players {
+		if p.State == "DEAD" || InSafeZone(p.X, p.Z) {
+			continue
+		}
+		dist := distance(e, p)
+		if dist > SightRange {
+			continue
+		}
+		if e.Threat[p.ID] == 0 && e.AITarget != p.ID && !w.hasLineOfSight(e, p) {
+			continue
+		}
+		if dist < minDist {
+			minDist = dist
+			target = p
+		}
+		if t := e.Threat[p.ID]; t > maxThreat {
+			hated, hatedDist, maxThreat = p, dist, t
+		}
+	}
+	if hated != nil {
+		return hated, hatedDist
+	}
+	return target, minDist
+}
+
+func distance(a, b *Entity) float64 {
+	dx := b.X - a.X
+	dz := b.Z - a.Z
+	return math.Sqrt(dx*dx + dz*dz)
+}
+
+// shouldFlee is true once per life when health drops below the profile's threshold.
+func (e *Entity) shouldFlee(profile EnemyProfile) bool {
+	if profile.FleeAt <= 0 || e.Fled || e.IsElite() || e.MaxHealth <= 0 {
+		return false
+	}
+	return float64(e.Health)/float64(e.MaxHealth) < profile.FleeAt
+}
+
+// resetAI clears AI state when an enemy respawns.
+func (e *Entity) resetAI() {
+	e.AIState = AIIdle
+	e.AITarget = ""
+	e.Fled = false
+	e.IsCharging = false
+	e.Path = nil
+	e.PathFailed = false
+}
+
+// callForHelp points nearby allies at the caller's target. Caller must hold the lock.
+func (w *World) callForHelp(caller, target *Entity) {
+	for _, ally := range w.Entities {
+		if ally == caller || ally.Type != TypeEnemy || ally.State == "DEAD" {
+			continue
+		}
+		if distance(caller, ally) <= HelpRadius && ally.Threat[target.ID] == 0 {
+			ally.addThreat(target.ID, HelpThreat)
+		}
+	}
+}
+
+func (w *World) chase(e, target *Entity, dt float64) {
+	e.TargetX = target.X
+	e.TargetZ = target.Z
+	e.State = "MOVING"
+	if !w.navigate(e, target.X, target.Z, e.Speed*dt) {
+		e.State = "IDLE"
+	}
+}
+
+func (w *World) fleeFrom(e, target *Entity, dt float64) {
+	e.State = "MOVING"
+	if !w.stepAway(e, target.X, target.Z, e.Speed*dt) {
+		e.State = "IDLE"
+	}
+}
+
+// tryAttack attacks when off cooldown. Caller must hold the lock.
+func (w *World) tryAttack(e *Entity, t *aiTick) {
+	if t.Now.Sub(e.LastAttackTime) >= e.AttackCooldown {
+		w.enemyAttack(e, t.Target, t.Profile)
+		e.LastAttackTime = t.Now
+		e.State = "ATTACKING" // Client can play animation
+		return
+	}
+	// Only reset to IDLE once the attack animation (500ms) has played
+	if t.Now.Sub(e.LastAttackTime) > 500*time.Millisecond && e.State == "ATTACKING" {
+		e.State = "IDLE"
+	}
+}
+
+// chaseBehavior runs at the target and attacks in range.
+type chaseBehavior struct{}
+
+func (chaseBehavior) Accepts(e *Entity, t *aiTick) bool { return true }
+
+func (chaseBehavior) Engage(w *World, e *Entity, t *aiTick) {
+	if t.inReach() {
+		w.tryAttack(e, t)
+		return
+	}
+	w.chase(e, t.Target, t.DT)
+}
+
+// Idle wanders around the spawn point.
+func (chaseBehavior) Idle(w *World, e *Entity, t *aiTick) {
+	dx := e.TargetX - e.X
+	dz := e.TargetZ - e.Z
+	if math.Sqrt(dx*dx+dz*dz) < 0.5 || (e.TargetX == 0 && e.TargetZ == 0) {
+		angle := rand.Float64() * 2 * math.Pi
+		dist := rand.Float64() * RoamRadius
+		e.TargetX = e.SpawnX + math.Cos(angle)*dist
+		e.TargetZ = e.SpawnZ + math.Sin(angle)*dist
+		e.State = "MOVING"
+	}
+	if !w.navigate(e, e.TargetX, e.TargetZ, e.Speed*t.DT) {
+		e.TargetX = e.SpawnX
+		e.TargetZ = e.SpawnZ
+	}
+}
+
+// swarmBehavior calls the pack on a new target and spreads around it.
+type swarmBehavior struct{ chaseBehavior }
+
+func (swarmBehavior) Engage(w *World, e *Entity, t *aiTick) {
+	if e.AITarget != t.Target.ID {
+		w.callForHelp(e, t.Target)
+	}
+	if t.inReach() {
+		w.tryAttack(e, t)
+		return
+	}
+	// Each member heads for its own slot on a ring around the target
+	x, z := swarmSlot(e, t.Target, t.Profile.Range*0.8)
+	e.TargetX, e.TargetZ = x, z
+	e.State = "MOVING"
+	if !w.navigate(e, x, z, e.Speed*t.DT) {
+		e.State = "IDLE"
+	}
+}
+
+// swarmSlot is a stable point around the target picked from the enemy's ID.
+func swarmSlot(e, target *Entity, radius float64) (float64, float64) {
+	h := fnv.New32a()
+	h.Write([]byte(e.ID))
+	angle := float64(h.Sum32()%360) * math.Pi / 180
+	return target.X + math.Cos(angle)*radius, target.Z + math.Sin(angle)*radius
+}
+
+// kiteBehavior backs away from close targets and attacks from range.
+type kiteBehavior struct{ chaseBehavior }
+
+func (kiteBehavior) Engage(w *World, e *Entity, t *aiTick) {
+	if t.Dist < KiteDistance {
+		e.State = "MOVING"
+		if w.stepAway(e, t.Target.X, t.Target.Z, e.Speed*t.DT) {
+			return
+		}
+		// Cornered against the safe zone: stand and fight
+	}
+	if t.inReach() {
+		w.tryAttack(e, t)
+		return
+	}
+	w.chase(e, t.Target, t.DT)
+}
+
+// chargeBehavior rushes targets at mid range and hits on arrival.
+type chargeBehavior struct{ chaseBehavior }
+
+func (chargeBehavior) Engage(w *World, e *Entity, t *aiTick) {
+	if !e.IsCharging && t.Visible && t.Dist >= ChargeMinRange && t.Dist <= ChargeMaxRange && !t.Now.Before(e.ChargeReady) {
+		e.IsCharging = true
+		e.ChargeTargetX = t.Target.X
+		e.ChargeTargetZ = t.Target.Z
+		e.ChargeReady = t.Now.Add(ChargeCooldown)
+	}
+	if !e.IsCharging {
+		chaseBehavior{}.Engage(w, e, t)
+		return
+	}
+
+	e.State = "MOVING"
+	moved := w.stepToward(e, e.ChargeTargetX, e.ChargeTargetZ, e.Speed*ChargeSpeed*t.DT)
+	dx := e.ChargeTargetX - e.X
+	dz := e.ChargeTargetZ - e.Z
+	if moved && math.Sqrt(dx*dx+dz*dz) > 0.01 {
+		return
+	}
+	// Arrived or blocked: slam whoever is in reach
+	e.IsCharging = false
+	if distance(e, t.Target) <= t.Profile.Range && w.hasLineOfSight(e, t.Target) {
+		e.LastAttackTime = t.Now
+		e.State = "ATTACKING"
+		if hit := w.weaponHit(e, t.Target); hit.Landed() {
+			w.stun(t.Target, ChargeStunDuration)
+		}
+	} else {
+		e.State = "IDLE"
+	}
+}
+
+// guardBehavior holds its spawn point and only fights near it.
+type guardBehavior struct{}
+
+func (guardBehavior) Accepts(e *Entity, t *aiTick) bool {
+	dx := t.Target.X - e.SpawnX
+	dz := t.Target.Z - e.SpawnZ
+	return math.Sqrt(dx*dx+dz*dz) <= GuardRadius+t.Profile.Range
+}
+
+func (guardBehavior) Engage(w *World, e *Entity, t *aiTick) {
+	if t.inReach() {
+		w.tryAttack(e, t)
+		return
+	}
+	// Close in, but never past the edge of the post
+	dx := t.Target.X - e.SpawnX
+	dz := t.Target.Z - e.SpawnZ
+	d := math.Sqrt(dx*dx + dz*dz)
+	x, z := t.Target.X, t.Target.Z
+	if d > GuardRadius {
+		x = e.SpawnX + dx/d*GuardRadius
+		z = e.SpawnZ + dz/d*GuardRadius
+	}
+	e.State = "MOVING"
+	if !w.navigate(e, x, z, e.Speed*t.DT) || (x == e.X && z == e.Z) {
+		e.State = "IDLE"
+	}
+}
+
+// Idle walks back to the post and waits there.
+func (guardBehavior) Idle(w *World, e *Entity, t *aiTick) {
+	e.TargetX, e.TargetZ = e.SpawnX, e.SpawnZ
+	dx := e.SpawnX - e.X
+	dz := e.SpawnZ - e.Z
+	if math.Sqrt(dx*dx+dz*dz) < 0.5 {
+		e.State = "IDLE"
+		return
+	}
+	e.State = "MOVING"
+	w.navigate(e, e.SpawnX, e.SpawnZ, e.Speed*t.DT)
+}
@@ -0,0 +1,215 @@
+package game
+
+import (
+	"testing"
+	"time"
+)
+
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) Now() time.Time          { return c.t }
+func (c *fakeClock) Advance(d time.Duration) { c.t = c.t.Add(d) }
+
+// aiWorld is an empty world on a fake clock with one player.
+func aiWorld(px, pz float64) (*World, *fakeClock, *Entity) {
+	w := NewWorld()
+	w.Entities = map[string]*Entity{}
+	clock := &fakeClock{t: time.Now()}
+	w.Clock = clock.Now
+
+	player := &Entity{ID: "p1", Type: TypePlayer, Health: 1000, MaxHealth: 1000, X: px, Z: pz}
+	w.Entities[player.ID] = player
+	return w, clock, player
+}
+
+func addEnemy(w *World, id, subType string, x, z float64) *Entity {
+	e := &Entity{ID: id, Type: TypeEnemy, SubType: subType, Level: 1, Health: 100, MaxHealth: 100, Damage: 10, Speed: 4,
+		X: x, Z: z, SpawnX: x, SpawnZ: z, AttackCooldown: 1500 * time.Millisecond, State: "IDLE"}
+	e.applyEnemyProfile()
+	w.Entities[id] = e
+	return e
+}
+
+// tick advances the fake clock alongside the simulation.
+func tick(w *World, clock *fakeClock, n int) {
+	for i := 0; i < n; i++ {
+		clock.Advance(50 * time.Millisecond)
+		w.Update(0.05)
+	}
+}
+
+func TestSkeletonsSwarmAndCallThePack(t *testing.T) {
+	fixedRolls(t)
+	w, clock, player := aiWorld(100, 0)
+	a := addEnemy(w, "Skeleton-a", "Skeleton", 100, 20)
+	b := addEnemy(w, "Skeleton-b", "Skeleton", 100, 35) // 15 from a; 35 from the player
+
+	tick(w, clock, 1)
+	if a.AIState != AIEngage || a.AITarget != player.ID {
+		t.Fatalf("Skeleton state = %s target %q", a.AIState, a.AITarget)
+	}
+	if b.Threat[player.ID] == 0 {
+		t.Error("Nearby skeleton should have been called to the fight")
+	}
+
+	tick(w, clock, 200)
+	if distance(a, player) > MeleeRange || distance(b, player) > MeleeRange {
+		t.Fatalf("Swarm should reach the player: %.1f %.1f", distance(a, player), distance(b, player))
+	}
+	if distance(a, b) < 0.5 {
+		t.Error("Swarm members should spread around the target, not stack")
+	}
+	if player.Health >= 1000 {
+		t.Error("Swarm should attack")
+	}
+}
+
+func TestImpsKiteAwayThenShoot(t *testing.T) {
+	w, clock, player := aiWorld(200, 0)
+	imp := addEnemy(w, "Imp-x", "Imp", 204, 0)
+
+	tick(w, clock, 1)
+	if imp.X <= 204 || len(projectiles(w)) != 0 {
+		t.Fatalf("Imp should back off without shooting, at %.2f with %d bolts", imp.X, len(projectiles(w)))
+	}
+
+	tick(w, clock, 40)
+	if distance(imp, player) < KiteDistance {
+		t.Errorf("Imp should keep its distance, at %.1f", distance(imp, player))
+	}
+	if imp.LastAttackTime.IsZero() {
+		t.Error("Imp should shoot once at range")
+	}
+}
+
+func TestDemonOrcsChargeOnCooldown(t *testing.T) {
+	fixedRolls(t)
+	w, clock, player := aiWorld(300, 0)
+	orc := addEnemy(w, "DemonOrc-x", "DemonOrc", 312, 0)
+
+	tick(w, clock, 1)
+	if !orc.IsCharging {
+		t.Fatal("DemonOrc should charge from mid range")
+	}
+	moved := 312 - orc.X
+	if moved < orc.Speed*ChargeSpeed*0.05*0.99 {
+		t.Errorf("Charge moved %.2f, want charge speed", moved)
+	}
+
+	tick(w, clock, 20)
+	if orc.IsCharging || player.Health >= 1000 {
+		t.Errorf("Charge should end in a hit: charging %v health %d", orc.IsCharging, player.Health)
+	}
+
+	// Step back out; the charge is still on cooldown so it walks
+	player.X = 290
+	orc.X = 302
+	tick(w, clock, 1)
+	if orc.IsCharging {
+		t.Error("Charge should respect its cooldown")
+	}
+	clock.Advance(ChargeCooldown)
+	orc.X = 302
+	tick(w, clock, 1)
+	if !orc.IsCharging {
+		t.Error("Charge should be ready again after the cooldown")
+	}
+}
+
+func TestConstructsGuardTheirPost(t *testing.T) {
+	w, clock, player := aiWorld(400, 40)
+	c := addEnemy(w, "Construct-x", "Construct", 400, 0)
+
+	// Seen but far from the post: ignored
+	tick(w, clock, 10)
+	if c.AIState != AIIdle || c.X != 400 || c.Z != 0 || len(projectiles(w)) != 0 {
+		t.Fatalf("Guard should hold its post, state %s at %.1f,%.1f", c.AIState, c.X, c.Z)
+	}
+
+	// Inside the post: engages from range without leaving
+	player.Z = 20
+	tick(w, clock, 1)
+	if c.AIState != AIEngage || len(projectiles(w)) != 1 {
+		t.Fatalf("Guard should engage, state %s bolts %d", c.AIState, len(projectiles(w)))
+	}
+
+	// Dragged off the post with nobody near: walks back
+	player.Z = 100
+	c.Z = -10
+	tick(w, clock, 100)
+	if distance(c, &Entity{X: c.SpawnX, Z: c.SpawnZ}) > 0.5 || c.State != "IDLE" {
+		t.Errorf("Guard should return to post, at %.1f,%.1f", c.X, c.Z)
+	}
+}
+
+func TestFleeAtLowHealthCallsForHelp(t *testing.T) {
+	w, clock, player := aiWorld(200, 0)
+	imp := addEnemy(w, "Imp-x", "Imp", 215, 0)
+	ally := addEnemy(w, "Imp-y", "Imp", 230, 0)
+	ally.Threat = nil
+	imp.Health = 20 // Below the Imp's 30% threshold
+
+	tick(w, clock, 1)
+	if imp.AIState != AIFlee {
+		t.Fatalf("Imp state = %s, want flee", imp.AIState)
+	}
+	if ally.Threat[player.ID] == 0 {
+		t.Error("Fleeing should alert nearby allies")
+	}
+	start := distance(imp, player)
+	tick(w, clock, 20)
+	if distance(imp, player) <= start || imp.AIState != AIFlee {
+		t.Errorf("Imp should keep running, at %.1f state %s", distance(imp, player), imp.AIState)
+	}
+
+	// Flees once per life, then fights again
+	clock.Advance(FleeDuration)
+	tick(w, clock, 1)
+	if imp.AIState != AIEngage {
+		t.Errorf("After fleeing state = %s, want engage", imp.AIState)
+	}
+
+	imp.State = "DEAD"
+	imp.LastAttackTime = time.Now().Add(-time.Minute)
+	tick(w, clock, 1)
+	if imp.Fled || imp.AIState != AIIdle {
+		t.Error("Respawn should reset AI state")
+	}
+}
+
+func TestSightGatesAggroRangedAttacksAndProjectiles(t *testing.T) {
+	w, clock, player := aiWorld(110, 0)
+	w.Collision = NewCollisionMap(WorldFile{Bounds: 400, CellSize: 1, Colliders: []Collider{
+		{Kind: "wall", MinX: 99, MinZ: -20, MaxX: 101, MaxZ: 20},
+	}})
+
+	// Behind the wall: nobody notices the player
+	skeleton := addEnemy(w, "Skeleton-x", "Skeleton", 95, 10)
+	tick(w, clock, 1)
+	if skeleton.AIState != AIIdle {
+		t.Errorf("Skeleton aggroed through a wall: %s", skeleton.AIState)
+	}
+	delete(w.Entities, skeleton.ID)
+
+	// A hurt Imp hunts its attacker but only shoots once it has sight
+	imp := addEnemy(w, "Imp-x", "Imp", 95, 0)
+	imp.addThreat(player.ID, 10)
+	tick(w, clock, 1)
+	if imp.AIState != AIEngage || len(projectiles(w)) != 0 || imp.LastAttackTime.After(time.Time{}) {
+		t.Fatalf("Imp should move for sight without shooting, state %s bolts %d", imp.AIState, len(projectiles(w)))
+	}
+	for i := 0; i < 200 && imp.LastAttackTime.IsZero(); i++ {
+		tick(w, clock, 1)
+	}
+	if imp.LastAttackTime.IsZero() || !w.hasLineOfSight(imp, player) {
+		t.Errorf("Imp should get around the wall and shoot, at %.1f,%.1f", imp.X, imp.Z)
+	}
+
+	// Bolts stop at terrain
+	bolt := &Entity{ID: "bolt", Type: TypeProjectile, SubType: "FireBolt", X: 98, Z: 0, VelX: 20, Radius: 0.8, Damage: 10, Hostile: true, ExpireTime: clock.Now().Add(time.Second)}
+	w.Entities[bolt.ID] = bolt
+	tick(w, clock, 1)
+	if _, ok := w.Entities[bolt.ID]; ok {
+		t.Error("Projectile should be stopped by the wall")
+	}
+}
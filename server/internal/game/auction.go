@@ -0,0 +1,114 @@
+package game
+
+import "time"
+
+// Auction house rules. Listings themselves live in the database; the world
+// only moves items and gold in and out of the player's hands.
+const (
+	AuctionFeePercent  = 5 // Cut taken from the seller on a sale
+	MaxAuctionListings = 10
+	MaxAuctionPrice    = 10000000
+)
+
+// Allowed listing durations (in hours)
+var AuctionDurations = map[int]time.Duration{
+	12: 12 * time.Hour,
+	24: 24 * time.Hour,
+	48: 48 * time.Hour,
+}
+
+// AuctionProceeds is what the seller receives after the house fee.
+func AuctionProceeds(price int) int {
+	fee := price * AuctionFeePercent / 100
+	return price - fee
+}
+
+// PerformAuctionList takes an item out of the player's inventory so it can be listed.
+func (w *World) PerformAuctionList(playerID, itemID string, price int) (*Item, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	player, ok := w.Entities[playerID]
+	if !ok || player.State == "DEAD" {
+		return nil, false
+	}
+	if !InSafeZone(player.X, player.Z) {
+		return nil, false
+	}
+	if price < 1 || price > MaxAuctionPrice {
+		return nil, false
+	}
+
+	item, ok := player.removeInventoryItem(itemID)
+	if !ok {
+		return nil, false
+	}
+	return &item, true
+}
+
+// PerformAuctionBuy charges the buyer for a listing. The item itself is handed
+// over with GiveItem once the listing has been claimed in the database.
+func (w *World) PerformAuctionBuy(playerID string, price int) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	player, ok := w.Entities[playerID]
+	if !ok || player.State == "DEAD" {
+		return false
+	}
+	if !InSafeZone(player.X, player.Z) {
+		return false
+	}
+	if price < 1 || player.Gold < price {
+		return false
+	}
+	if len(player.Inventory) >= MaxInventorySize {
+		return false
+	}
+
+	player.Gold -= price
+	return true
+}
+
+// GiveItem adds an item to a player's inventory if there is room.
+func (w *World) GiveItem(playerID string, item Item) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	player, ok := w.Entities[playerID]
+	if !ok {
+		return false
+	}
+	if len(player.Inventory) >= MaxInventorySize {
+		return false
+	}
+	player.Inventory = append(player.Inventory, item)
+	return true
+}
+
+// AddGold credits gold to an online player without counting it as earned (refunds).
+func (w *World) AddGold(playerID string, amount int) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	player, ok := w.Entities[playerID]
+	if !ok {
+		return false
+	}
+	player.Gold += amount
+	return true
+}
+
+// EarnGold credits sale proceeds to an online player and counts them towards
+// lifetime gold earned.
+func (w *World) EarnGold(playerID string, amount int) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	player, ok := w.Entities[playerID]
+	if !ok {
+		return false
+	}
+	player.earnGold(amount)
+	return true
+}
@@ -0,0 +1,106 @@
+package game
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestAuctionListTakesItemInTown(t *testing.T) {
+	w := NewWorld()
+	p := &Entity{ID: "player-1", Type: TypePlayer, State: "IDLE", X: 5, Z: 7,
+		Inventory: []Item{{ID: "item-1", Name: "Iron Sword"}}}
+	w.AddEntity(p)
+
+	if _, ok := w.PerformAuctionList(p.ID, "item-1", 0); ok {
+		t.Error("Listing for 0 gold should fail")
+	}
+	if _, ok := w.PerformAuctionList(p.ID, "item-1", MaxAuctionPrice+1); ok {
+		t.Error("Listing above the price cap should fail")
+	}
+
+	p.X, p.Z = 200, 200
+	if _, ok := w.PerformAuctionList(p.ID, "item-1", 100); ok {
+		t.Error("Listing outside town should fail")
+	}
+	if len(p.Inventory) != 1 {
+		t.Fatal("Failed listings should keep the item")
+	}
+
+	p.X, p.Z = 5, 7
+	item, ok := w.PerformAuctionList(p.ID, "item-1", 100)
+	if !ok || item.ID != "item-1" {
+		t.Fatalf("PerformAuctionList = %+v, %v", item, ok)
+	}
+	if len(p.Inventory) != 0 {
+		t.Error("Listed item should leave the inventory")
+	}
+	if _, ok := w.PerformAuctionList(p.ID, "item-1", 100); ok {
+		t.Error("The same item cannot be listed twice")
+	}
+}
+
+func TestAuctionBuyChargesBuyer(t *testing.T) {
+	w := NewWorld()
+	p := &Entity{ID: "player-1", Type: TypePlayer, State: "IDLE", X: 5, Z: 7, Gold: 50}
+	w.AddEntity(p)
+
+	if w.PerformAuctionBuy(p.ID, 100) {
+		t.Error("Buying without enough gold should fail")
+	}
+	if p.Gold != 50 {
+		t.Errorf("Rejected buy changed gold to %d", p.Gold)
+	}
+
+	p.Gold = 150
+	if !w.PerformAuctionBuy(p.ID, 100) {
+		t.Fatal("PerformAuctionBuy failed with enough gold")
+	}
+	if p.Gold != 50 {
+		t.Errorf("Gold after buy = %d, want 50", p.Gold)
+	}
+
+	if AuctionProceeds(100) != 95 {
+		t.Errorf("Proceeds = %d, want 95 after the fee", AuctionProceeds(100))
+	}
+}
+
+func TestGiveItemAndAddGold(t *testing.T) {
+	w := NewWorld()
+	p := &Entity{ID: "player-1", Type: TypePlayer, State: "IDLE"}
+	for i := 0; i < MaxInventorySize; i++ {
+		p.Inventory = append(p.Inventory, Item{ID: fmt.Sprintf("item-%d", i)})
+	}
+	w.AddEntity(p)
+
+	if w.GiveItem(p.ID, Item{ID: "extra"}) {
+		t.Error("GiveItem should refuse a full inventory")
+	}
+	p.Inventory = p.Inventory[1:]
+	if !w.GiveItem(p.ID, Item{ID: "extra"}) || len(p.Inventory) != MaxInventorySize {
+		t.Error("GiveItem should fill a free slot")
+	}
+
+	if w.AddGold("offline", 10) {
+		t.Error("AddGold should refuse an offline player")
+	}
+	if !w.AddGold(p.ID, 10) || p.Gold != 10 {
+		t.Errorf("AddGold = %d gold, want 10", p.Gold)
+	}
+}
+
+func TestAuctionProceedsCountAsEarned(t *testing.T) {
+	w := NewWorld()
+	p := &Entity{ID: "player-1", Type: TypePlayer, State: "IDLE", Profile: NewProfile()}
+	w.AddEntity(p)
+
+	w.AddGold(p.ID, 40) // Refund
+	if !w.EarnGold(p.ID, 95) {
+		t.Fatal("EarnGold failed for an online player")
+	}
+	if p.Gold != 135 || p.Profile.Stats.GoldEarned != 95 {
+		t.Errorf("Gold %d earned %d, want 135 / 95", p.Gold, p.Profile.Stats.GoldEarned)
+	}
+	if w.EarnGold("offline", 10) {
+		t.Error("EarnGold should refuse an offline player")
+	}
+}
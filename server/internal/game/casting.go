@@ -0,0 +1,162 @@
+package game
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	UnarmedSpeed       = 1.0 // Attacks per second without a weapon
+	MinAttackCooldown  = 250 * time.Millisecond
+	CastMoveTolerance  = 0.25 // Moving further than this interrupts a cast
+	ChargeStunDuration = 500 * time.Millisecond
+)
+
+// WeaponSpeeds are base attacks per second by weapon base
+var WeaponSpeeds = map[string]float64{
+	"Iron Sword":   1.0,
+	"Steel Dagger": 1.4,
+	"Wooden Staff": 0.8,
+	"Cleric Mace":  0.9,
+}
+
+// ClassAbility is the ability a class casts with the ability key
+type ClassAbility struct {
+	Name     string
+	ManaCost int
+}
+
+var ClassAbilities = map[string]ClassAbility{
+	"Fighter": {Name: "Charge", ManaCost: 20},
+	"Wizard":  {Name: "Fireball", ManaCost: 30},
+	"Rogue":   {Name: "Dagger", ManaCost: 15},
+	"Cleric":  {Name: "Spirits", ManaCost: 40},
+}
+
+// CastTimes are wind-ups before an ability fires, by class ability name or
+// support ability ID. Abilities without an entry are instant.
+var CastTimes = map[string]time.Duration{
+	"Fireball":  600 * time.Millisecond,
+	"heal":      1 * time.Second,
+	"mass_heal": 1500 * time.Millisecond,
+	"resurrect": 3 * time.Second,
+}
+
+// Cast is an ability being wound up. It is broadcast with the caster so other
+// clients can show a cast bar.
+type Cast struct {
+	Ability  string    `json:"ability"`
+	TargetID string    `json:"targetId,omitempty"`
+	Duration int64     `json:"duration"` // ms
+	EndsAt   int64     `json:"endsAt"`   // Unix ms
+	TargetX  float64   `json:"-"`
+	TargetZ  float64   `json:"-"`
+	Support  bool      `json:"-"` // A SupportAbility ID rather than a class ability
+	StartX   float64   `json:"-"`
+	StartZ   float64   `json:"-"`
+	End      time.Time `json:"-"`
+}
+
+// weaponSpeed returns the base attacks per second of a main hand weapon.
+func weaponSpeed(item Item) float64 {
+	if item.Slot != "mainHand" {
+		return UnarmedSpeed
+	}
+	if s, ok := WeaponSpeeds[item.Base]; ok {
+		return s
+	}
+	// Items from before base tracking only carry their name
+	for base, s := range WeaponSpeeds {
+		if strings.Contains(item.Name, base) {
+			return s
+		}
+	}
+	return UnarmedSpeed
+}
+
+// attackCooldown is the time between basic attacks for a weapon and Attack Speed.
+func attackCooldown(weapon Item, attackSpeed float64) time.Duration {
+	rate := weaponSpeed(weapon) * attackSpeed
+	if rate <= 0 {
+		rate = UnarmedSpeed
+	}
+	cooldown := time.Duration(float64(time.Second) / rate)
+	if cooldown < MinAttackCooldown {
+		cooldown = MinAttackCooldown
+	}
+	return cooldown
+}
+
+// castTime is an ability's wind-up after Cast Speed.
+func (e *Entity) castTime(ability string) time.Duration {
+	base := CastTimes[ability]
+	if base <= 0 || e.CastSpeed <= 0 {
+		return base
+	}
+	return time.Duration(float64(base) / e.CastSpeed)
+}
+
+func (e *Entity) isStunned(now time.Time) bool {
+	return now.Before(e.StunnedUntil)
+}
+
+// startCast begins winding up an ability. Returns false if the ability is
+// instant and should fire now. Caller must hold the lock.
+func (w *World) startCast(e *Entity, cast Cast) bool {
+	d := e.castTime(cast.Ability)
+	if d <= 0 {
+		return false
+	}
+	now := w.now()
+	cast.Duration = d.Milliseconds()
+	cast.End = now.Add(d)
+	cast.EndsAt = cast.End.UnixMilli()
+	cast.StartX, cast.StartZ = e.X, e.Z
+	e.Casting = &cast
+	e.State = "CASTING"
+	w.queueEvent(CombatEvent{Type: EventCast, SourceID: e.ID, TargetID: cast.TargetID, Amount: int(cast.Duration), Source: cast.Ability, X: e.X, Z: e.Z})
+	return true
+}
+
+// interruptCast cancels a wind-up without spending mana or cooldown.
+// Caller must hold the lock.
+func (w *World) interruptCast(e *Entity) {
+	if e.Casting == nil {
+		return
+	}
+	w.queueEvent(CombatEvent{Type: EventInterrupt, SourceID: e.ID, TargetID: e.Casting.TargetID, Source: e.Casting.Ability, X: e.X, Z: e.Z})
+	e.Casting = nil
+	if e.State == "CASTING" {
+		e.State = "IDLE"
+	}
+}
+
+// updateCast fires a finished wind-up. Caller must hold the lock.
+func (w *World) updateCast(e *Entity) {
+	cast := e.Casting
+	if cast == nil || w.now().Before(cast.End) {
+		return
+	}
+	e.Casting = nil
+	e.State = "IDLE"
+	if cast.Support {
+		if def := GetSupportAbility(cast.Ability); def != nil {
+			w.releaseSupportAbility(e, def, cast.TargetID)
+		}
+		return
+	}
+	w.releaseAbility(e, cast.TargetX, cast.TargetZ)
+}
+
+// stun stops an entity acting for a while, breaking casts and charges.
+// Caller must hold the lock.
+func (w *World) stun(e *Entity, d time.Duration) {
+	if e.State == "DEAD" {
+		return
+	}
+	if until := w.now().Add(d); until.After(e.StunnedUntil) {
+		e.StunnedUntil = until
+	}
+	e.IsCharging = false
+	w.interruptCast(e)
+}
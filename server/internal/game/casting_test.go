@@ -0,0 +1,153 @@
+package game
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAttackCooldownFromWeaponAndSpeed(t *testing.T) {
+	dagger := Item{Name: "Steel Dagger", Base: "Steel Dagger", Slot: "mainHand"}
+	staff := Item{Name: "Old Wooden Staff", Slot: "mainHand"} // Pre-base item
+
+	if got := attackCooldown(Item{}, 1.0); got != time.Second {
+		t.Errorf("Unarmed = %v, want 1s", got)
+	}
+	if got := attackCooldown(staff, 1.0); got != 1250*time.Millisecond {
+		t.Errorf("Staff = %v, want 1.25s", got)
+	}
+	if got := attackCooldown(dagger, 2.0); got < 357*time.Millisecond || got > 358*time.Millisecond {
+		t.Errorf("Fast dagger = %v", got)
+	}
+	if got := attackCooldown(dagger, 10); got != MinAttackCooldown {
+		t.Errorf("Cooldown floor = %v, want %v", got, MinAttackCooldown)
+	}
+
+	rogue := &Entity{Type: TypePlayer, SubType: "Rogue", Level: 1, BaseStats: Stats{Dexterity: 20, Vitality: 10},
+		Equipment: map[string]Item{"mainHand": dagger}}
+	rogue.RecalculateStats()
+	want := attackCooldown(dagger, rogue.AttackSpeed)
+	if rogue.AttackSpeed <= 1 || rogue.AttackCooldown != want || want >= time.Second {
+		t.Errorf("Rogue speed %.2f cooldown %v, want %v", rogue.AttackSpeed, rogue.AttackCooldown, want)
+	}
+}
+
+// castWorld is a world on a fake clock with a Wizard in the wilds.
+func castWorld() (*World, *fakeClock, *Entity) {
+	w, clock, _ := aiWorld(300, 300)
+	delete(w.Entities, "p1")
+	wizard := &Entity{ID: "wiz", Type: TypePlayer, SubType: "Wizard", Level: 1, X: 100, Z: 100,
+		BaseStats: Stats{Intelligence: 20, Vitality: 10}}
+	wizard.RecalculateStats()
+	wizard.Health, wizard.Mana = wizard.MaxHealth, wizard.MaxMana
+	w.Entities[wizard.ID] = wizard
+	return w, clock, wizard
+}
+
+func TestFireballWindsUpThenFires(t *testing.T) {
+	w, clock, wizard := castWorld()
+	mana := wizard.Mana
+
+	w.PerformAbility(wizard.ID, 120, 100, "")
+	if wizard.Casting == nil || wizard.State != "CASTING" || wizard.Casting.Ability != "Fireball" {
+		t.Fatalf("Expected a Fireball cast, got %+v state %s", wizard.Casting, wizard.State)
+	}
+	if len(projectiles(w)) != 0 || wizard.Mana != mana {
+		t.Error("Nothing should fire or be spent during the wind-up")
+	}
+	data, _ := json.Marshal(w.GetState()[wizard.ID])
+	if !strings.Contains(string(data), `"casting":{"ability":"Fireball"`) {
+		t.Errorf("Cast should be broadcast, got %s", data)
+	}
+	if evs := w.DrainEvents(); len(evs) != 1 || evs[0].Type != EventCast || evs[0].Amount != 600 {
+		t.Errorf("Cast events = %+v", evs)
+	}
+
+	w.PerformAbility(wizard.ID, 120, 100, "")
+	tick(w, clock, 11) // 550ms
+	if len(projectiles(w)) != 0 {
+		t.Fatal("Fired before the cast time")
+	}
+	tick(w, clock, 2)
+	if wizard.Casting != nil || len(projectiles(w)) != 1 || wizard.Mana >= mana {
+		t.Errorf("Fireball should fire once: casting %v bolts %d mana %d", wizard.Casting, len(projectiles(w)), wizard.Mana)
+	}
+
+	// Cast Speed shortens the wind-up
+	wizard.CastSpeed = 2
+	if wizard.castTime("Fireball") != 300*time.Millisecond || wizard.castTime("Dagger") != 0 {
+		t.Errorf("Cast times: fireball %v dagger %v", wizard.castTime("Fireball"), wizard.castTime("Dagger"))
+	}
+}
+
+func TestMovementInterruptsCast(t *testing.T) {
+	w, clock, wizard := castWorld()
+	mana := wizard.Mana
+
+	w.PerformAbility(wizard.ID, 120, 100, "")
+	w.MovePlayer(wizard.ID, 100.1, 0, 100, 0, "IDLE", 0)
+	if wizard.Casting == nil || wizard.State != "CASTING" {
+		t.Fatal("Tiny adjustments should not interrupt")
+	}
+	w.MovePlayer(wizard.ID, 102, 0, 100, 0, "MOVING", 0)
+	if wizard.Casting != nil || wizard.State != "MOVING" {
+		t.Fatalf("Moving should interrupt: %+v", wizard.Casting)
+	}
+	tick(w, clock, 20)
+	if len(projectiles(w)) != 0 || wizard.Mana != mana {
+		t.Error("Interrupted cast should not fire or cost mana")
+	}
+	interrupted := false
+	for _, ev := range w.DrainEvents() {
+		if ev.Type == EventInterrupt && ev.SourceID == wizard.ID && ev.Source == "Fireball" {
+			interrupted = true
+		}
+	}
+	if !interrupted {
+		t.Error("Expected an interrupt event")
+	}
+
+	// No cooldown was spent, so it can be cast again straight away
+	w.PerformAbility(wizard.ID, 120, 100, "")
+	if wizard.Casting == nil {
+		t.Error("Recast after interrupt failed")
+	}
+}
+
+func TestStunsInterruptAndBlockActions(t *testing.T) {
+	fixedRolls(t)
+	w, clock, wizard := castWorld()
+	enemy := addEnemy(w, "Skeleton-x", "Skeleton", 110, 100)
+
+	w.PerformAbility(wizard.ID, 120, 100, "")
+	w.stun(wizard, time.Second)
+	if wizard.Casting != nil {
+		t.Fatal("Stun should interrupt the cast")
+	}
+	w.PerformAbility(wizard.ID, 120, 100, "")
+	if wizard.Casting != nil {
+		t.Error("Stunned players cannot cast")
+	}
+	if _, ok := w.PerformAttack(wizard.ID, enemy.ID); ok {
+		t.Error("Stunned players cannot attack")
+	}
+
+	clock.Advance(time.Second)
+	if _, ok := w.PerformAttack(wizard.ID, enemy.ID); !ok {
+		t.Error("Attack should work once the stun wears off")
+	}
+}
+
+func TestDemonOrcChargeStuns(t *testing.T) {
+	fixedRolls(t)
+	w, clock, player := aiWorld(300, 0)
+	addEnemy(w, "DemonOrc-x", "DemonOrc", 312, 0)
+
+	for i := 0; i < 30 && player.StunnedUntil.IsZero(); i++ {
+		tick(w, clock, 1)
+	}
+	if !player.isStunned(clock.Now()) {
+		t.Error("Charge slam should stun its target")
+	}
+}
@@ -0,0 +1,248 @@
+package game
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sync"
+)
+
+const (
+	AgentRadius   = 0.5  // Collision radius of players and enemies
+	gridClearance = 0.25 // Extra margin so path cells keep agents off walls
+)
+
+// world.json mirrors the client's WorldGenerator: the town fence with its
+// south gate. Both sides must change together.
+//
+//go:embed world.json
+var defaultWorldFile []byte
+
+// Collider is a static axis-aligned obstacle on the ground plane
+type Collider struct {
+	Kind string  `json:"kind"` // wall, fence or rock
+	Name string  `json:"name,omitempty"`
+	MinX float64 `json:"minX"`
+	MinZ float64 `json:"minZ"`
+	MaxX float64 `json:"maxX"`
+	MaxZ float64 `json:"maxZ"`
+}
+
+// WorldFile is the static layout loaded at startup
+type WorldFile struct {
+	Bounds    float64    `json:"bounds"`   // Full width of the square world, centred on the origin
+	CellSize  float64    `json:"cellSize"` // Pathfinding grid resolution
+	Colliders []Collider `json:"colliders"`
+}
+
+// safeZone is the town as a box. Enemies may not cross it (see InSafeZone).
+var safeZone = Collider{Kind: "safezone", MinX: -SafeZoneHalf, MinZ: -SafeZoneHalf, MaxX: SafeZoneHalf, MaxZ: SafeZoneHalf}
+
+// CollisionMap answers movement queries against the static layout. The grid
+// marks cells an enemy's centre cannot occupy, so it also blocks the town.
+type CollisionMap struct {
+	Half      float64
+	CellSize  float64
+	Colliders []Collider
+	width     int
+	blocked   []bool
+}
+
+// LoadCollisionMap parses a world file and builds its grid.
+func LoadCollisionMap(data []byte) (*CollisionMap, error) {
+	var f WorldFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, err
+	}
+	if f.Bounds <= 0 || f.CellSize <= 0 {
+		return nil, fmt.Errorf("world file needs positive bounds and cellSize")
+	}
+	return NewCollisionMap(f), nil
+}
+
+var (
+	defaultMapOnce sync.Once
+	defaultMap     *CollisionMap
+)
+
+// DefaultCollisionMap is the embedded world matching the client. It is built
+// once and shared; maps are read-only after construction.
+func DefaultCollisionMap() *CollisionMap {
+	defaultMapOnce.Do(func() {
+		m, err := LoadCollisionMap(defaultWorldFile)
+		if err != nil {
+			panic("invalid embedded world.json: " + err.Error())
+		}
+		defaultMap = m
+	})
+	return defaultMap
+}
+
+func NewCollisionMap(f WorldFile) *CollisionMap {
+	m := &CollisionMap{
+		Half:      f.Bounds / 2,
+		CellSize:  f.CellSize,
+		Colliders: f.Colliders,
+		width:     int(math.Ceil(f.Bounds / f.CellSize)),
+	}
+	m.blocked = make([]bool, m.width*m.width)
+	pad := AgentRadius + gridClearance
+	for _, c := range m.Colliders {
+		x0, z0 := m.cellOf(c.MinX-pad, c.MinZ-pad)
+		x1, z1 := m.cellOf(c.MaxX+pad, c.MaxZ+pad)
+		for cz := z0; cz <= z1; cz++ {
+			for cx := x0; cx <= x1; cx++ {
+				x, z := m.cellCenter(cx, cz)
+				if c.distance(x, z) < pad {
+					m.blocked[cz*m.width+cx] = true
+				}
+			}
+		}
+	}
+
+	// Enemies plan over the grid and stepToward refuses to enter town
+	x0, z0 := m.cellOf(safeZone.MinX, safeZone.MinZ)
+	x1, z1 := m.cellOf(safeZone.MaxX, safeZone.MaxZ)
+	for cz := z0; cz <= z1; cz++ {
+		for cx := x0; cx <= x1; cx++ {
+			if x, z := m.cellCenter(cx, cz); InSafeZone(x, z) {
+				m.blocked[cz*m.width+cx] = true
+			}
+		}
+	}
+	return m
+}
+
+// distance from a point to the box (0 inside).
+func (c Collider) distance(x, z float64) float64 {
+	dx := math.Max(math.Max(c.MinX-x, 0), x-c.MaxX)
+	dz := math.Max(math.Max(c.MinZ-z, 0), z-c.MaxZ)
+	return math.Sqrt(dx*dx + dz*dz)
+}
+
+// cellOf returns the grid cell containing a point, clamped to the grid.
+func (m *CollisionMap) cellOf(x, z float64) (int, int) {
+	clamp := func(v int) int {
+		if v < 0 {
+			return 0
+		}
+		if v >= m.width {
+			return m.width - 1
+		}
+		return v
+	}
+	return clamp(int((x + m.Half) / m.CellSize)), clamp(int((z + m.Half) / m.CellSize))
+}
+
+func (m *CollisionMap) cellCenter(cx, cz int) (float64, float64) {
+	return (float64(cx)+0.5)*m.CellSize - m.Half, (float64(cz)+0.5)*m.CellSize - m.Half
+}
+
+func (m *CollisionMap) cellBlocked(cx, cz int) bool {
+	if cx < 0 || cz < 0 || cx >= m.width || cz >= m.width {
+		return true
+	}
+	return m.blocked[cz*m.width+cx]
+}
+
+func (m *CollisionMap) inBounds(x, z, radius float64) bool {
+	return x >= -m.Half+radius && x <= m.Half-radius && z >= -m.Half+radius && z <= m.Half-radius
+}
+
+// Collides reports whether a circle overlaps an obstacle or leaves the world.
+func (m *CollisionMap) Collides(x, z, radius float64) bool {
+	if !m.inBounds(x, z, radius) {
+		return true
+	}
+	for _, c := range m.Colliders {
+		if c.distance(x, z) < radius {
+			return true
+		}
+	}
+	return false
+}
+
+// SegmentClear reports whether a circle can slide from one point to another
+// without touching an obstacle.
+func (m *CollisionMap) SegmentClear(x0, z0, x1, z1, radius float64) bool {
+	dx := x1 - x0
+	dz := z1 - z0
+	dist := math.Sqrt(dx*dx + dz*dz)
+	steps := int(math.Ceil(dist / math.Max(radius/2, 0.25)))
+	for i := 1; i <= steps; i++ {
+		f := float64(i) / float64(steps)
+		if m.Collides(x0+dx*f, z0+dz*f, radius) {
+			return false
+		}
+	}
+	return true
+}
+
+// pathClear reports whether an enemy can walk straight between two points
+// without touching terrain or crossing the town.
+func (m *CollisionMap) pathClear(x0, z0, x1, z1 float64) bool {
+	return !safeZone.intersectsSegment(x0, z0, x1, z1) && m.SegmentClear(x0, z0, x1, z1, AgentRadius)
+}
+
+// Resolve pushes a moving circle out of obstacles and back inside the world,
+// like the client's CollisionManager. A move that ends inside an obstacle
+// keeps the previous position. Long jumps are not swept, so the client's
+// respawn/unstuck teleport to town still works.
+func (m *CollisionMap) Resolve(fromX, fromZ, x, z, radius float64) (float64, float64) {
+	x = math.Max(-m.Half+radius, math.Min(m.Half-radius, x))
+	z = math.Max(-m.Half+radius, math.Min(m.Half-radius, z))
+	for _, c := range m.Colliders {
+		d := c.distance(x, z)
+		if d >= radius {
+			continue
+		}
+		if d == 0 {
+			return fromX, fromZ
+		}
+		cx := math.Max(c.MinX, math.Min(c.MaxX, x))
+		cz := math.Max(c.MinZ, math.Min(c.MaxZ, z))
+		x += (x - cx) / d * (radius - d)
+		z += (z - cz) / d * (radius - d)
+	}
+	return x, z
+}
+
+// intersectsSegment is a slab test of the line from (x0,z0) to (x1,z1) against the box.
+func (c Collider) intersectsSegment(x0, z0, x1, z1 float64) bool {
+	tmin, tmax := 0.0, 1.0
+	for _, axis := range [2][4]float64{{x0, x1 - x0, c.MinX, c.MaxX}, {z0, z1 - z0, c.MinZ, c.MaxZ}} {
+		p, d, lo, hi := axis[0], axis[1], axis[2], axis[3]
+		if math.Abs(d) < 1e-12 {
+			if p < lo || p > hi {
+				return false
+			}
+			continue
+		}
+		t1, t2 := (lo-p)/d, (hi-p)/d
+		if t1 > t2 {
+			t1, t2 = t2, t1
+		}
+		tmin = math.Max(tmin, t1)
+		tmax = math.Min(tmax, t2)
+		if tmin > tmax {
+			return false
+		}
+	}
+	return true
+}
+
+// LineOfSight reports whether nothing stands between two points.
+func (m *CollisionMap) LineOfSight(x0, z0, x1, z1 float64) bool {
+	for _, c := range m.Colliders {
+		if c.intersectsSegment(x0, z0, x1, z1) {
+			return false
+		}
+	}
+	return true
+}
+
+// hasLineOfSight reports whether a can see b. Caller must hold the lock.
+func (w *World) hasLineOfSight(a, b *Entity) bool {
+	return w.Collision == nil || w.Collision.LineOfSight(a.X, a.Z, b.X, b.Z)
+}
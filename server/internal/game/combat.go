@@ -0,0 +1,136 @@
+package game
+
+import (
+	"math"
+	"math/rand"
+	"strings"
+)
+
+type HitOutcome string
+
+const (
+	HitNormal HitOutcome = "hit"
+	HitMiss   HitOutcome = "miss"
+	HitDodge  HitOutcome = "dodge"
+	HitBlock  HitOutcome = "block"
+)
+
+const (
+	BaseMissChance = 0.05
+	MissPerLevel   = 0.01 // Extra miss chance per level the target is above the attacker
+	MaxMissChance  = 0.3
+
+	CritPerDex     = 0.002 // 10 Dexterity = +2% crit
+	CritMultiplier = 1.5
+	MaxCritChance  = 0.75
+
+	DodgePerDex    = 0.0025
+	MaxDodgeChance = 0.4
+
+	ShieldBlockChance = 0.15
+	BlockPerStr       = 0.002
+	MaxBlockChance    = 0.5
+	BlockReduction    = 0.6 // Share of damage a block absorbs
+)
+
+// DefaultAttackRange is the reach of a melee basic attack
+const DefaultAttackRange = 5.0
+
+// AttackRanges are basic attack reaches for ranged classes and NPCs
+var AttackRanges = map[string]float64{
+	"Wizard":        30,
+	"Rogue":         25,
+	"DwarfSalesman": 6,
+}
+
+// AttackRange returns the basic attack reach of a class or NPC subtype.
+func AttackRange(subType string) float64 {
+	if r, ok := AttackRanges[subType]; ok {
+		return r
+	}
+	return DefaultAttackRange
+}
+
+// ShieldBases are offHand bases that can block
+var ShieldBases = map[string]bool{
+	"Wooden Shield": true,
+}
+
+// HitResult is the outcome of one resolved attack
+type HitResult struct {
+	Outcome HitOutcome `json:"outcome"`
+	Crit    bool       `json:"crit,omitempty"`
+	Damage  int        `json:"damage"`
+	Type    DamageType `json:"type"`
+}
+
+// Landed reports whether the attack connected (blocked hits still land).
+func (r HitResult) Landed() bool {
+	return r.Outcome == HitNormal || r.Outcome == HitBlock
+}
+
+// combatRoll is the random source for hit resolution (replaced in tests)
+var combatRoll = rand.Float64
+
+func isShield(item Item) bool {
+	if item.Slot != "offHand" || item.Broken() {
+		return false
+	}
+	if item.Base != "" {
+		return ShieldBases[item.Base]
+	}
+	// Items from before base tracking only carry their name
+	return strings.Contains(item.Name, "Shield")
+}
+
+// combatChances derives crit, dodge and block chances from stats and gear.
+func combatChances(stats Stats, critPct int, hasShield bool) (crit, dodge, block float64) {
+	crit = math.Min(MaxCritChance, float64(critPct)/100.0+float64(stats.Dexterity)*CritPerDex)
+	dodge = math.Min(MaxDodgeChance, float64(stats.Dexterity)*DodgePerDex)
+	if hasShield {
+		block = math.Min(MaxBlockChance, ShieldBlockChance+float64(stats.Strength)*BlockPerStr)
+	}
+	return crit, dodge, block
+}
+
+// missChance grows when the target out-levels the attacker.
+func missChance(attacker, target *Entity) float64 {
+	chance := BaseMissChance
+	if diff := target.Level - attacker.Level; diff > 0 {
+		chance += float64(diff) * MissPerLevel
+	}
+	return math.Min(MaxMissChance, chance)
+}
+
+// resolveHit rolls miss, dodge, block and crit for a weapon attack and
+// returns the damage after defense. Resistances are applied by ApplyDamage.
+func resolveHit(attacker, target *Entity) HitResult {
+	dtype := attacker.Element
+	if dtype == "" {
+		dtype = DamagePhysical
+	}
+	if combatRoll() < missChance(attacker, target) {
+		return HitResult{Outcome: HitMiss, Type: dtype}
+	}
+	if combatRoll() < target.DodgeChance {
+		return HitResult{Outcome: HitDodge, Type: dtype}
+	}
+
+	result := HitResult{Outcome: HitNormal, Type: dtype}
+	raw := float64(attacker.Damage)
+	if combatRoll() < attacker.CritChance {
+		result.Crit = true
+		raw *= CritMultiplier
+	}
+
+	damage := int(raw) - target.Defense
+	if combatRoll() < target.BlockChance {
+		result.Outcome = HitBlock
+		damage = int(float64(damage) * (1 - BlockReduction))
+	}
+	if damage < 1 {
+		damage = 1
+	}
+	result.Damage = damage
+	return result
+}
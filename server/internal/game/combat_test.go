@@ -0,0 +1,139 @@
+package game
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// fixedRolls makes combatRoll return the given values in order.
+func fixedRolls(t *testing.T, rolls ...float64) {
+	i := 0
+	combatRoll = func() float64 {
+		if i >= len(rolls) {
+			return 0.99
+		}
+		r := rolls[i]
+		i++
+		return r
+	}
+	t.Cleanup(func() { combatRoll = rand.Float64 })
+}
+
+func approx(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestCombatChancesFromStats(t *testing.T) {
+	crit, dodge, block := combatChances(Stats{Dexterity: 50, Strength: 25}, 5, false)
+	if !approx(crit, 0.05+50*CritPerDex) || !approx(dodge, 50*DodgePerDex) || block != 0 {
+		t.Errorf("crit %f dodge %f block %f", crit, dodge, block)
+	}
+	_, _, block = combatChances(Stats{Strength: 25}, 0, true)
+	if !approx(block, ShieldBlockChance+25*BlockPerStr) {
+		t.Errorf("Shield block = %f", block)
+	}
+	crit, dodge, block = combatChances(Stats{Dexterity: 10000, Strength: 10000}, 100, true)
+	if crit != MaxCritChance || dodge != MaxDodgeChance || block != MaxBlockChance {
+		t.Errorf("Caps not applied: %f %f %f", crit, dodge, block)
+	}
+}
+
+func TestShieldGrantsBlock(t *testing.T) {
+	e := &Entity{Level: 1, BaseStats: Stats{Strength: 10, Vitality: 10}}
+	e.Equipment = map[string]Item{"offHand": {Slot: "offHand", Base: "Spell Tome"}}
+	e.RecalculateStats()
+	if e.BlockChance != 0 {
+		t.Errorf("Spell Tome granted block %f", e.BlockChance)
+	}
+	e.Equipment["offHand"] = Item{Slot: "offHand", Base: "Wooden Shield"}
+	e.RecalculateStats()
+	if e.BlockChance <= 0 {
+		t.Error("Shield did not grant block")
+	}
+	e.Equipment["offHand"] = Item{Slot: "offHand", Base: "Wooden Shield", MaxDurability: 10}
+	e.RecalculateStats()
+	if e.BlockChance != 0 {
+		t.Error("Broken shield still blocks")
+	}
+}
+
+func TestResolveHitOutcomes(t *testing.T) {
+	attacker := &Entity{Level: 1, Damage: 20, CritChance: 0.5}
+	target := &Entity{Level: 1, Defense: 4, DodgeChance: 0.2, BlockChance: 0.3}
+
+	// Rolls: miss, dodge, crit, block
+	fixedRolls(t, 0.01)
+	if r := resolveHit(attacker, target); r.Outcome != HitMiss || r.Damage != 0 || r.Landed() {
+		t.Errorf("Expected miss, got %+v", r)
+	}
+
+	fixedRolls(t, 0.5, 0.1)
+	if r := resolveHit(attacker, target); r.Outcome != HitDodge || r.Damage != 0 {
+		t.Errorf("Expected dodge, got %+v", r)
+	}
+
+	fixedRolls(t, 0.5, 0.5, 0.9, 0.9)
+	if r := resolveHit(attacker, target); r.Outcome != HitNormal || r.Crit || r.Damage != 16 {
+		t.Errorf("Expected plain hit for 16, got %+v", r)
+	}
+
+	fixedRolls(t, 0.5, 0.5, 0.1, 0.9)
+	if r := resolveHit(attacker, target); !r.Crit || r.Damage != 26 {
+		t.Errorf("Expected crit for 26, got %+v", r)
+	}
+
+	fixedRolls(t, 0.5, 0.5, 0.1, 0.1)
+	if r := resolveHit(attacker, target); r.Outcome != HitBlock || !r.Crit || r.Damage != 10 || !r.Landed() {
+		t.Errorf("Expected blocked crit for 10, got %+v", r)
+	}
+}
+
+func TestMissChanceGrowsWithLevelGap(t *testing.T) {
+	low := &Entity{Level: 1}
+	high := &Entity{Level: 10}
+	if missChance(high, low) != BaseMissChance {
+		t.Error("Higher level attacker should use the base miss chance")
+	}
+	if missChance(low, high) <= BaseMissChance {
+		t.Error("Out-levelled attacker should miss more")
+	}
+}
+
+func TestAttackRangeAndLineOfSight(t *testing.T) {
+	fixedRolls(t)
+	w := NewWorld()
+	w.Entities = map[string]*Entity{}
+	w.Collision = wallMap()
+
+	wizard := &Entity{ID: "w1", Type: TypePlayer, SubType: "Wizard", Level: 1, Damage: 5, X: 80, Z: 50}
+	fighter := &Entity{ID: "f1", Type: TypePlayer, SubType: "Fighter", Level: 1, Damage: 5, X: 80, Z: 50}
+	enemy := &Entity{ID: "Skeleton-x", Type: TypeEnemy, SubType: "Skeleton", Level: 1, Health: 100, MaxHealth: 100, X: 105, Z: 50}
+	for _, e := range []*Entity{wizard, fighter, enemy} {
+		w.Entities[e.ID] = e
+	}
+
+	if AttackRange("Wizard") != 30 || AttackRange("Cleric") != DefaultAttackRange {
+		t.Errorf("Ranges: wizard %.0f cleric %.0f", AttackRange("Wizard"), AttackRange("Cleric"))
+	}
+	if _, ok := w.PerformAttack(wizard.ID, enemy.ID); !ok {
+		t.Error("Wizard should hit at 25 units in the open")
+	}
+	if _, ok := w.PerformAttack(fighter.ID, enemy.ID); ok {
+		t.Error("Fighter should not reach 25 units")
+	}
+
+	// Same distance with the wall at x=100 in between
+	wizard.LastAttackTime = time.Time{}
+	wizard.Z, enemy.Z = 0, 0
+	if _, ok := w.PerformAttack(wizard.ID, enemy.ID); ok {
+		t.Error("Wall should block the attack")
+	}
+
+	enemy.X = 140
+	wizard.X = 80
+	if _, ok := w.PerformAttack(wizard.ID, enemy.ID); ok {
+		t.Error("60 units is out of Wizard range")
+	}
+}
@@ -0,0 +1,212 @@
+package game
+
+// DamageType is the element a hit deals. The four elements match the realms
+// of Aethelgard; physical damage is only reduced by Defense.
+type DamageType string
+
+const (
+	DamagePhysical DamageType = "physical"
+	DamageEarth    DamageType = "earth" // Iron Weald
+	DamageAir      DamageType = "air"   // Crystalline Spire
+	DamageFire     DamageType = "fire"  // Shifting Sands
+	DamageWater    DamageType = "water" // Abyssal Well
+)
+
+const (
+	MaxResistance = 75   // Resistances above this are wasted
+	MinResistance = -100 // Weaknesses at most double damage
+)
+
+// Ability and effect damage types
+var AbilityDamageTypes = map[string]DamageType{
+	"Fireball":  DamageFire,
+	"FireTrail": DamageFire,
+	"Explode":   DamageFire,
+	"Dagger":    DamagePhysical,
+	"Spirits":   DamageAir,
+}
+
+// AbilityDamageType returns the element of an ability, projectile or effect.
+func AbilityDamageType(source string) DamageType {
+	if t, ok := AbilityDamageTypes[source]; ok {
+		return t
+	}
+	return DamagePhysical
+}
+
+// For returns the resistance to one damage type (0 for physical).
+func (r Resistances) For(t DamageType) int {
+	switch t {
+	case DamageFire:
+		return r.Fire
+	case DamageWater:
+		return r.Water
+	case DamageEarth:
+		return r.Earth
+	case DamageAir:
+		return r.Air
+	}
+	return 0
+}
+
+// mitigate reduces damage by the target's resistance to its type.
+func (e *Entity) mitigate(amount int, t DamageType) int {
+	res := e.Resistances.For(t)
+	if res > MaxResistance {
+		res = MaxResistance
+	}
+	if res < MinResistance {
+		res = MinResistance
+	}
+	out := amount * (100 - res) / 100
+	if out < 1 && amount > 0 {
+		out = 1
+	}
+	return out
+}
+
+// DamageSpec describes one instance of damage
+type DamageSpec struct {
+	Amount  int
+	Type    DamageType
+	Source  string     // Ability, projectile or effect name; empty for weapon attacks
+	Outcome HitOutcome // From resolveHit; empty is a plain hit
+	Crit    bool
+	OnHit   bool // Heal the source by its Life on Hit
+	Procs   bool // Trigger the source's on-hit procs
+}
+
+// DamageEvent is the result of ApplyDamage; it is queued as a hit or crit combat event
+type DamageEvent struct {
+	SourceID string     `json:"sourceId"`
+	TargetID string     `json:"targetId"`
+	Amount   int        `json:"amount"`
+	Absorbed int        `json:"absorbed,omitempty"`
+	Type     DamageType `json:"type"`
+	Source   string     `json:"source,omitempty"`
+	Outcome  HitOutcome `json:"outcome"`
+	Crit     bool       `json:"crit,omitempty"`
+	Killed   bool       `json:"killed,omitempty"`
+}
+
+// DamageHook runs inside ApplyDamage. Pre hooks may change ev.Amount before
+// it is applied; post hooks see the final amount. source may be nil.
+type DamageHook func(w *World, source, target *Entity, spec *DamageSpec, ev *DamageEvent)
+
+func defaultPreDamageHooks() []DamageHook {
+	return []DamageHook{resistHook, absorbHook}
+}
+
+func defaultPostDamageHooks() []DamageHook {
+	return []DamageHook{statsHook, threatHook, lifeOnHitHook, durabilityHook, procHook}
+}
+
+// ApplyDamage is the single place damage is mitigated, applied and reported.
+// It runs the pre and post hooks, handles death for players and enemies, and
+// emits a damage event. Caller must hold the lock.
+func (w *World) ApplyDamage(source, target *Entity, spec DamageSpec) DamageEvent {
+	if spec.Type == "" {
+		spec.Type = DamagePhysical
+	}
+	if spec.Outcome == "" {
+		spec.Outcome = HitNormal
+	}
+	ev := DamageEvent{
+		TargetID: target.ID,
+		Amount:   spec.Amount,
+		Type:     spec.Type,
+		Source:   spec.Source,
+		Outcome:  spec.Outcome,
+		Crit:     spec.Crit,
+	}
+	if source != nil {
+		ev.SourceID = source.ID
+	}
+	if target.State == "DEAD" {
+		return DamageEvent{}
+	}
+
+	for _, hook := range w.PreDamageHooks {
+		hook(w, source, target, &spec, &ev)
+	}
+	target.Health -= ev.Amount
+	for _, hook := range w.PostDamageHooks {
+		hook(w, source, target, &spec, &ev)
+	}
+
+	if target.Health <= 0 {
+		ev.Killed = true
+		w.handleDeath(target, source)
+	}
+	w.emitDamage(ev, target)
+	return ev
+}
+
+// resistHook applies the target's elemental resistance.
+func resistHook(w *World, source, target *Entity, spec *DamageSpec, ev *DamageEvent) {
+	ev.Amount = target.mitigate(ev.Amount, spec.Type)
+}
+
+// absorbHook lets damage shields soak damage before health.
+func absorbHook(w *World, source, target *Entity, spec *DamageSpec, ev *DamageEvent) {
+	if target.Absorb <= 0 {
+		return
+	}
+	soaked := ev.Amount
+	if soaked > target.Absorb {
+		soaked = target.Absorb
+	}
+	target.Absorb -= soaked
+	ev.Amount -= soaked
+	ev.Absorbed = soaked
+}
+
+func statsHook(w *World, source, target *Entity, spec *DamageSpec, ev *DamageEvent) {
+	if target.Type == TypeEnemy {
+		source.recordDamage(ev.Amount)
+	}
+}
+
+// threatHook makes enemies remember who hurt them.
+func threatHook(w *World, source, target *Entity, spec *DamageSpec, ev *DamageEvent) {
+	if source == nil || source.Type != TypePlayer || target.Type != TypeEnemy {
+		return
+	}
+	target.addThreat(source.ID, ev.Amount+ev.Absorbed)
+}
+
+func lifeOnHitHook(w *World, source, target *Entity, spec *DamageSpec, ev *DamageEvent) {
+	if spec.OnHit && source != nil && source.LifeOnHit > 0 {
+		w.heal(source, source, source.LifeOnHit, "LifeOnHit")
+	}
+}
+
+// durabilityHook wears the attacker's weapon and the defender's armor on weapon hits.
+func durabilityHook(w *World, source, target *Entity, spec *DamageSpec, ev *DamageEvent) {
+	if spec.Source != "" {
+		return
+	}
+	if source != nil && source.Type == TypePlayer {
+		source.wearWeapon()
+	}
+	if target.Type == TypePlayer {
+		target.wearArmor()
+	}
+}
+
+func procHook(w *World, source, target *Entity, spec *DamageSpec, ev *DamageEvent) {
+	if spec.Procs && source != nil && source.Type == TypePlayer {
+		w.triggerProcs(source, target, ProcOnHit, spec.Source)
+	}
+}
+
+// addThreat adds hate towards a player.
+func (e *Entity) addThreat(playerID string, amount int) {
+	if amount <= 0 {
+		return
+	}
+	if e.Threat == nil {
+		e.Threat = make(map[string]int)
+	}
+	e.Threat[playerID] += amount
+}
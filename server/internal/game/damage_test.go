@@ -0,0 +1,164 @@
+package game
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMitigateByResistance(t *testing.T) {
+	e := &Entity{Resistances: Resistances{Fire: 50, Water: -25, Air: 200}}
+
+	if got := e.mitigate(100, DamageFire); got != 50 {
+		t.Errorf("Fire = %d, want 50", got)
+	}
+	if got := e.mitigate(100, DamageWater); got != 125 {
+		t.Errorf("Weakness = %d, want 125", got)
+	}
+	if got := e.mitigate(100, DamageAir); got != 100-MaxResistance {
+		t.Errorf("Capped air = %d, want %d", got, 100-MaxResistance)
+	}
+	if got := e.mitigate(100, DamagePhysical); got != 100 {
+		t.Errorf("Physical = %d, want 100", got)
+	}
+	if got := e.mitigate(1, DamageAir); got != 1 {
+		t.Errorf("Minimum damage = %d, want 1", got)
+	}
+}
+
+func TestEnemyProfilesFollowRealms(t *testing.T) {
+	construct := &Entity{SubType: "Construct"}
+	construct.applyEnemyProfile()
+	imp := &Entity{SubType: "Imp"}
+	imp.applyEnemyProfile()
+
+	if construct.Element != DamageAir || imp.Element != DamageFire {
+		t.Errorf("Attack elements: construct %s imp %s", construct.Element, imp.Element)
+	}
+
+	// Fireball should matter: Constructs are weak to fire, Imps resist it
+	if construct.mitigate(100, DamageFire) <= imp.mitigate(100, DamageFire) {
+		t.Error("Fire should hurt Constructs more than Imps")
+	}
+
+	unknown := &Entity{SubType: "Mystery"}
+	unknown.applyEnemyProfile()
+	if unknown.Element != DamagePhysical {
+		t.Errorf("Unknown subtype element = %s", unknown.Element)
+	}
+}
+
+func TestProjectileDealsElementalDamage(t *testing.T) {
+	w := NewWorld()
+	w.Entities = map[string]*Entity{}
+
+	imp := &Entity{ID: "Imp-x", Type: TypeEnemy, SubType: "Imp", Level: 1, Health: 1000, MaxHealth: 1000, X: 300, Z: 300}
+	imp.applyEnemyProfile()
+	w.Entities[imp.ID] = imp
+	w.Entities["proj"] = &Entity{ID: "proj", Type: TypeProjectile, SubType: "Fireball", X: 300, Z: 300, Radius: 2, Damage: 100, Element: DamageFire, NoProc: true}
+
+	w.Update(0.01)
+
+	if got := 1000 - imp.Health; got != 50 {
+		t.Errorf("Fireball dealt %d to a fire-resistant Imp, want 50", got)
+	}
+}
+
+func TestApplyDamagePipeline(t *testing.T) {
+	w := NewWorld()
+	w.DrainEvents()
+
+	player := &Entity{ID: "p1", Type: TypePlayer, Level: 1, Health: 100, MaxHealth: 100, LifeOnHit: 5, Profile: NewProfile()}
+	enemy := &Entity{ID: "Skeleton-x", Type: TypeEnemy, SubType: "Skeleton", Level: 1, Health: 100, MaxHealth: 100, Absorb: 10}
+	w.Entities[player.ID] = player
+	w.Entities[enemy.ID] = enemy
+	player.Health = 50
+
+	ev := w.ApplyDamage(player, enemy, DamageSpec{Amount: 30, Source: "Dagger", OnHit: true})
+	if ev.Absorbed != 10 || ev.Amount != 20 || enemy.Health != 80 || enemy.Absorb != 0 {
+		t.Errorf("Absorb: %+v health %d", ev, enemy.Health)
+	}
+	if enemy.Threat[player.ID] != 30 {
+		t.Errorf("Threat = %d, want 30", enemy.Threat[player.ID])
+	}
+	if player.Health != 55 {
+		t.Errorf("Life on hit not applied: %d", player.Health)
+	}
+	if player.Profile.Stats.DamageDealt != 20 {
+		t.Errorf("DamageDealt = %d", player.Profile.Stats.DamageDealt)
+	}
+	events := w.DrainEvents()
+	if len(events) != 2 || events[0].Type != EventHeal || events[1].Type != EventHit ||
+		events[1].SourceID != "p1" || events[1].DamageType != DamagePhysical || events[1].Absorbed != 10 {
+		t.Errorf("Events = %+v", events)
+	}
+
+	// Custom hooks can extend the pipeline
+	w.PreDamageHooks = append(w.PreDamageHooks, func(w *World, source, target *Entity, spec *DamageSpec, ev *DamageEvent) {
+		ev.Amount *= 2
+	})
+	w.ApplyDamage(nil, enemy, DamageSpec{Amount: 5})
+	if enemy.Health != 70 {
+		t.Errorf("Custom hook not applied, health %d", enemy.Health)
+	}
+}
+
+func TestEnemyKillsPlayerThroughHandleDeath(t *testing.T) {
+	w := NewWorld()
+	w.Entities = map[string]*Entity{}
+
+	player := &Entity{ID: "p1", Type: TypePlayer, Level: 1, Health: 5, MaxHealth: 100, Profile: NewProfile(), IsCharging: true}
+	enemy := &Entity{ID: "Imp-x", Type: TypeEnemy, SubType: "Imp", Level: 1, Health: 50, MaxHealth: 50}
+	enemy.addThreat(player.ID, 50)
+	w.Entities[player.ID] = player
+	w.Entities[enemy.ID] = enemy
+
+	if ev := w.ApplyDamage(enemy, player, DamageSpec{Amount: 20}); !ev.Killed {
+		t.Error("Kill not reported")
+	}
+
+	if player.State != "DEAD" || player.Health != 0 || player.IsCharging {
+		t.Errorf("Player not killed cleanly: state %s health %d", player.State, player.Health)
+	}
+	if player.Profile.Stats.Deaths != 1 {
+		t.Errorf("Deaths = %d", player.Profile.Stats.Deaths)
+	}
+	if _, ok := enemy.Threat[player.ID]; ok {
+		t.Error("Enemy still holds threat on a dead player")
+	}
+	deaths := 0
+	for _, ev := range w.DrainEvents() {
+		if ev.Type == EventDeath && ev.TargetID == player.ID && ev.SourceID == enemy.ID {
+			deaths++
+		}
+	}
+	if deaths != 1 {
+		t.Errorf("Death events = %d, want 1", deaths)
+	}
+
+	// Dead targets take no further damage
+	if ev := w.ApplyDamage(enemy, player, DamageSpec{Amount: 20}); ev.Amount != 0 {
+		t.Errorf("Damaged a dead player: %+v", ev)
+	}
+}
+
+func TestSpiritsEmitDamageEvents(t *testing.T) {
+	w := NewWorld()
+	w.Entities = map[string]*Entity{}
+	w.DrainEvents()
+
+	cleric := &Entity{ID: "p1", Type: TypePlayer, SubType: "Cleric", Level: 1, Health: 100, MaxHealth: 100, X: 300, Z: 300,
+		BaseStats: Stats{Wisdom: 10}, SpiritsActive: true, SpiritEndTime: time.Now().Add(time.Second)}
+	enemy := &Entity{ID: "Construct-x", Type: TypeEnemy, SubType: "Construct", Level: 1, Health: 500, MaxHealth: 500, X: 305, Z: 300, State: "IDLE", AttackCooldown: time.Hour, LastAttackTime: time.Now()}
+	w.Entities[cleric.ID] = cleric
+	w.Entities[enemy.ID] = enemy
+
+	w.Update(0.01)
+
+	var sources []string
+	for _, ev := range w.DrainEvents() {
+		sources = append(sources, ev.Source)
+	}
+	if len(sources) != 1 || sources[0] != "Spirits" {
+		t.Errorf("Damage events = %v", sources)
+	}
+}
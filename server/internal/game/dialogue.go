@@ -0,0 +1,395 @@
+package game
+
+import (
+	"fmt"
+)
+
+type ConditionKind string
+
+const (
+	CondMinLevel         ConditionKind = "min_level"         // Level >= Level
+	CondQuestAvailable   ConditionKind = "quest_available"   // Quest Value can be accepted
+	CondQuestActive      ConditionKind = "quest_active"      // Quest Value accepted but not finished
+	CondQuestComplete    ConditionKind = "quest_complete"    // Quest Value ready to turn in
+	CondQuestDone        ConditionKind = "quest_done"        // Quest Value turned in
+	CondRegionRestored   ConditionKind = "region_restored"   // Realm Value restored
+	CondRegionUnrestored ConditionKind = "region_unrestored" // Realm Value not yet restored
+)
+
+type DialogueCondition struct {
+	Kind  ConditionKind
+	Value string
+	Level int
+}
+
+type ActionKind string
+
+const (
+	ActionOpenShop    ActionKind = "open_shop"
+	ActionGiveQuest   ActionKind = "give_quest"
+	ActionTurnInQuest ActionKind = "turn_in_quest"
+	ActionTeleport    ActionKind = "teleport"
+)
+
+type DialogueAction struct {
+	Kind    ActionKind
+	QuestID string
+	X, Z    float64
+}
+
+type DialogueChoice struct {
+	Text       string
+	Next       string // Node to show after choosing; empty ends the conversation
+	Conditions []DialogueCondition
+	Action     *DialogueAction
+}
+
+type DialogueNode struct {
+	Text    string
+	Choices []DialogueChoice
+}
+
+type DialogueTree struct {
+	Start string
+	Nodes map[string]DialogueNode
+}
+
+// DialogueView is what the client renders for the current node
+type DialogueView struct {
+	NPCID   string               `json:"npcId"`
+	Name    string               `json:"name"`
+	NodeID  string               `json:"nodeId"`
+	Text    string               `json:"text"`
+	Choices []DialogueChoiceView `json:"choices"`
+}
+
+type DialogueChoiceView struct {
+	Index int    `json:"index"` // Send back with the dialogue_choice message
+	Text  string `json:"text"`
+}
+
+// DialogueResult is the outcome of picking a choice
+type DialogueResult struct {
+	View     *DialogueView // Nil when the conversation ended
+	OpenShop bool
+	Quests   bool      // Quest log changed
+	Teleport *Teleport // Player was moved; the client must be told
+}
+
+// LoreNPC keeps the memory of one of Aethelgard's realms
+type LoreNPC struct {
+	ID      string
+	Name    string
+	Realm   string // Zone name
+	Element string
+	X, Z    float64
+}
+
+var LoreNPCs = []LoreNPC{
+	{ID: "npc-stonewarden", Name: "Stonewarden Brak", Realm: "Iron Weald", Element: "Earth", X: 0, Z: -65},
+	{ID: "npc-sandseer", Name: "Sandseer Kesh", Realm: "Shifting Sands", Element: "Fire", X: 165, Z: 0},
+	{ID: "npc-tidekeeper", Name: "Tidekeeper Maren", Realm: "Abyssal Well", Element: "Water", X: 0, Z: 265},
+	{ID: "npc-windcaller", Name: "Windcaller Aeris", Realm: "Crystalline Spire", Element: "Air", X: -365, Z: 0},
+}
+
+// Where "Return to town" teleports land
+const townReturnX, townReturnZ = 0.0, -10.0
+
+var loreText = map[string]struct{ Intro, History, Restored string }{
+	"Iron Weald": {
+		Intro:    "The Weald was a forest of iron-barked oaks once. Now the dead march between the stumps.",
+		History:  "Earth remembers everything. When the Sundering split Aethelgard, the Weald held its shape the longest. The skeletons are the ones who refused to leave.",
+		Restored: "Listen. The roots are singing again. You did this.",
+	},
+	"Shifting Sands": {
+		Intro:    "Careful where you step. The dunes here burn, and the mirage lies.",
+		History:  "The Sands were a sea of glass, forged by the Fire realm's great forges. The imps stoke a mirage to hide what the forges became.",
+		Restored: "The mirage has broken. For the first time in an age I can see the horizon.",
+	},
+	"Abyssal Well": {
+		Intro:    "Do not look down the Well too long. It looks back.",
+		History:  "Water was the realm of memory. The scholars of the Well recorded everything, until the Drowning took them and the demons came up from below.",
+		Restored: "The Well runs clear. I can hear the scholars' voices in it, faint but calm.",
+	},
+	"Crystalline Spire": {
+		Intro:    "Up here the wind carries voices. Most of them are machines now.",
+		History:  "The Spire sang with the Air realm's harmonies. The Constructs were its caretakers; without a song to keep, they guard silence instead.",
+		Restored: "The Spire sings again. Even the Constructs have stopped to listen.",
+	},
+}
+
+// Dialogues by NPC entity ID
+var Dialogues = buildDialogues()
+
+func buildDialogues() map[string]*DialogueTree {
+	trees := map[string]*DialogueTree{
+		MerchantID: {
+			Start: "start",
+			Nodes: map[string]DialogueNode{
+				"start": {
+					Text: "Welcome, traveler! Finest wares this side of the Weald.",
+					Choices: []DialogueChoice{
+						{Text: "Show me your wares.", Action: &DialogueAction{Kind: ActionOpenShop}},
+						{Text: "Heard any news?", Next: "news"},
+						{Text: "Farewell."},
+					},
+				},
+				"news": {
+					Text: "Folk say if enough of those beasts fall, the realms remember what they were. Talk to the keepers out in the wilds if you want the long version.",
+					Choices: []DialogueChoice{
+						{Text: "Back to business.", Next: "start"},
+					},
+				},
+			},
+		},
+	}
+
+	travel := make([]DialogueChoice, 0, len(LoreNPCs))
+	for _, npc := range LoreNPCs {
+		trees[npc.ID] = loreTree(npc)
+		travel = append(travel, DialogueChoice{
+			Text:       fmt.Sprintf("Take me to the %s.", npc.Realm),
+			Conditions: []DialogueCondition{{Kind: CondRegionRestored, Value: npc.Realm}},
+			Action:     &DialogueAction{Kind: ActionTeleport, X: npc.X, Z: npc.Z + 3},
+		})
+	}
+
+	greetings := map[string]string{
+		"npc-warden":  "Keep your blade ready. The town walls won't hold forever.",
+		"npc-scholar": "Ah, a field researcher! I have questions that need answers from out there.",
+	}
+	for _, g := range QuestGivers {
+		tree := questGiverTree(g.ID, greetings[g.ID])
+		if g.ID == "npc-warden" {
+			// The warden escorts travellers to realms that have been restored
+			start := tree.Nodes["start"]
+			start.Choices = append(travel, start.Choices...)
+			tree.Nodes["start"] = start
+		}
+		trees[g.ID] = tree
+	}
+	return trees
+}
+
+// questGiverTree builds offer/progress/turn-in branches for every quest the NPC gives.
+func questGiverTree(giverID, greeting string) *DialogueTree {
+	tree := &DialogueTree{Start: "start", Nodes: map[string]DialogueNode{}}
+	var choices []DialogueChoice
+
+	for _, q := range Quests {
+		if q.Giver != giverID {
+			continue
+		}
+		offer := "offer:" + q.ID
+		choices = append(choices,
+			DialogueChoice{
+				Text:       fmt.Sprintf("Any work for me? (%s)", q.Name),
+				Next:       offer,
+				Conditions: []DialogueCondition{{Kind: CondQuestAvailable, Value: q.ID}},
+			},
+			DialogueChoice{
+				Text:       fmt.Sprintf("About %s...", q.Name),
+				Next:       "progress:" + q.ID,
+				Conditions: []DialogueCondition{{Kind: CondQuestActive, Value: q.ID}},
+			},
+			DialogueChoice{
+				Text:       fmt.Sprintf("It's done. (%s)", q.Name),
+				Next:       "thanks",
+				Conditions: []DialogueCondition{{Kind: CondQuestComplete, Value: q.ID}},
+				Action:     &DialogueAction{Kind: ActionTurnInQuest, QuestID: q.ID},
+			},
+		)
+		tree.Nodes[offer] = DialogueNode{
+			Text: q.Description,
+			Choices: []DialogueChoice{
+				{Text: "I'll do it.", Action: &DialogueAction{Kind: ActionGiveQuest, QuestID: q.ID}},
+				{Text: "Not now.", Next: "start"},
+			},
+		}
+		tree.Nodes["progress:"+q.ID] = DialogueNode{
+			Text:    "Come back when it's done.",
+			Choices: []DialogueChoice{{Text: "I will.", Next: "start"}},
+		}
+	}
+	choices = append(choices, DialogueChoice{Text: "Farewell."})
+
+	tree.Nodes["start"] = DialogueNode{Text: greeting, Choices: choices}
+	tree.Nodes["thanks"] = DialogueNode{
+		Text:    "Well done. Here, you've earned this.",
+		Choices: []DialogueChoice{{Text: "Anything else?", Next: "start"}, {Text: "Farewell."}},
+	}
+	return tree
+}
+
+func loreTree(npc LoreNPC) *DialogueTree {
+	lore := loreText[npc.Realm]
+	restored := []DialogueCondition{{Kind: CondRegionRestored, Value: npc.Realm}}
+	unrestored := []DialogueCondition{{Kind: CondRegionUnrestored, Value: npc.Realm}}
+
+	return &DialogueTree{
+		Start: "start",
+		Nodes: map[string]DialogueNode{
+			"start": {
+				Text: lore.Intro,
+				Choices: []DialogueChoice{
+					{Text: fmt.Sprintf("Tell me about the %s.", npc.Realm), Next: "history"},
+					{Text: "How can this realm be restored?", Next: "restore", Conditions: unrestored},
+					{Text: "The realm feels different now.", Next: "restored", Conditions: restored},
+					{Text: fmt.Sprintf("What lies deeper in the realm of %s?", npc.Element), Next: "deeper",
+						Conditions: []DialogueCondition{{Kind: CondMinLevel, Level: 15}}},
+					{Text: "Take me back to town.", Action: &DialogueAction{Kind: ActionTeleport, X: townReturnX, Z: townReturnZ}},
+					{Text: "Farewell."},
+				},
+			},
+			"history": {
+				Text:    lore.History,
+				Choices: []DialogueChoice{{Text: "I see.", Next: "start"}},
+			},
+			"restore": {
+				Text:    fmt.Sprintf("Every creature that falls here loosens the grip of the Sundering. Slay %d of them, all of you together, and the %s will remember itself.", RegionRestoreKills, npc.Realm),
+				Choices: []DialogueChoice{{Text: "Then I'd better get to work.", Next: "start"}},
+			},
+			"restored": {
+				Text:    lore.Restored,
+				Choices: []DialogueChoice{{Text: "Good.", Next: "start"}},
+			},
+			"deeper": {
+				Text:    "Something older than the Sundering. When you are ready, the keepers of the other realms will tell you the rest.",
+				Choices: []DialogueChoice{{Text: "I'll be ready.", Next: "start"}},
+			},
+		},
+	}
+}
+
+func (w *World) spawnLoreNPCs() {
+	for _, npc := range LoreNPCs {
+		w.AddEntity(&Entity{
+			ID:      npc.ID,
+			Name:    npc.Name,
+			Type:    TypeNPC,
+			SubType: "LoreKeeper",
+			X:       npc.X,
+			Z:       npc.Z,
+			State:   "IDLE",
+		})
+	}
+}
+
+// dialogueConditionsMet checks every condition of a choice. Caller must hold the lock.
+func (w *World) dialogueConditionsMet(player *Entity, conds []DialogueCondition) bool {
+	for _, c := range conds {
+		ok := false
+		switch c.Kind {
+		case CondMinLevel:
+			ok = player.Level >= c.Level
+		case CondQuestAvailable:
+			def := GetQuest(c.Value)
+			ok = def != nil && player.canAcceptQuest(def)
+		case CondQuestActive:
+			i := player.activeQuest(c.Value)
+			ok = i >= 0 && !player.Quests[i].Complete
+		case CondQuestComplete:
+			i := player.activeQuest(c.Value)
+			ok = i >= 0 && player.Quests[i].Complete
+		case CondQuestDone:
+			ok = player.CompletedQuests[c.Value]
+		case CondRegionRestored:
+			ok = w.RestoredRegions[c.Value]
+		case CondRegionUnrestored:
+			ok = !w.RestoredRegions[c.Value]
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// dialogueView renders a node with the choices this player may see. Caller must hold the lock.
+func (w *World) dialogueView(player, npc *Entity, tree *DialogueTree, nodeID string) *DialogueView {
+	node, ok := tree.Nodes[nodeID]
+	if !ok {
+		return nil
+	}
+	view := &DialogueView{
+		NPCID:   npc.ID,
+		Name:    npc.Name,
+		NodeID:  nodeID,
+		Text:    node.Text,
+		Choices: make([]DialogueChoiceView, 0, len(node.Choices)),
+	}
+	for i, c := range node.Choices {
+		if w.dialogueConditionsMet(player, c.Conditions) {
+			view.Choices = append(view.Choices, DialogueChoiceView{Index: i, Text: c.Text})
+		}
+	}
+	return view
+}
+
+// PerformInteract starts a conversation with an NPC.
+func (w *World) PerformInteract(playerID, npcID string) (*DialogueView, bool) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	player, ok := w.Entities[playerID]
+	if !ok || player.State == "DEAD" || !w.nearNPC(player, npcID) {
+		return nil, false
+	}
+	tree, ok := Dialogues[npcID]
+	if !ok {
+		return nil, false
+	}
+	view := w.dialogueView(player, w.Entities[npcID], tree, tree.Start)
+	return view, view != nil
+}
+
+// PerformDialogueChoice picks a choice on a node, runs its action and returns the next node.
+func (w *World) PerformDialogueChoice(playerID, npcID, nodeID string, choice int) (*DialogueResult, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	player, ok := w.Entities[playerID]
+	if !ok || player.State == "DEAD" || !w.nearNPC(player, npcID) {
+		return nil, false
+	}
+	tree, ok := Dialogues[npcID]
+	if !ok {
+		return nil, false
+	}
+	node, ok := tree.Nodes[nodeID]
+	if !ok || choice < 0 || choice >= len(node.Choices) {
+		return nil, false
+	}
+	c := node.Choices[choice]
+	if !w.dialogueConditionsMet(player, c.Conditions) {
+		return nil, false
+	}
+
+	result := &DialogueResult{}
+	if a := c.Action; a != nil {
+		switch a.Kind {
+		case ActionOpenShop:
+			result.OpenShop = true
+		case ActionGiveQuest:
+			def := GetQuest(a.QuestID)
+			if def == nil || !w.acceptQuest(player, def) {
+				return nil, false
+			}
+			result.Quests = true
+		case ActionTurnInQuest:
+			def := GetQuest(a.QuestID)
+			if def == nil || !w.turnInQuest(player, def) {
+				return nil, false
+			}
+			result.Quests = true
+		case ActionTeleport:
+			result.Teleport = w.teleport(player, a.X, a.Z)
+			w.questReach(player)
+		}
+	}
+
+	if c.Next != "" {
+		result.View = w.dialogueView(player, w.Entities[npcID], tree, c.Next)
+	}
+	return result, true
+}
@@ -0,0 +1,128 @@
+package game
+
+import (
+	"testing"
+)
+
+func findChoice(view *DialogueView, text string) int {
+	for _, c := range view.Choices {
+		if c.Text == text {
+			return c.Index
+		}
+	}
+	return -1
+}
+
+func TestDialogueTreesAreConsistent(t *testing.T) {
+	for npcID, tree := range Dialogues {
+		if _, ok := tree.Nodes[tree.Start]; !ok {
+			t.Errorf("%s: missing start node %q", npcID, tree.Start)
+		}
+		for nodeID, node := range tree.Nodes {
+			for _, c := range node.Choices {
+				if c.Next != "" {
+					if _, ok := tree.Nodes[c.Next]; !ok {
+						t.Errorf("%s/%s: choice %q leads to unknown node %q", npcID, nodeID, c.Text, c.Next)
+					}
+				}
+				if c.Action != nil && c.Action.QuestID != "" && GetQuest(c.Action.QuestID) == nil {
+					t.Errorf("%s/%s: unknown quest %q", npcID, nodeID, c.Action.QuestID)
+				}
+			}
+		}
+	}
+	for _, npc := range LoreNPCs {
+		if ZoneAt(npc.X, npc.Z) == nil || ZoneAt(npc.X, npc.Z).Name != npc.Realm {
+			t.Errorf("%s is not standing in %s", npc.Name, npc.Realm)
+		}
+	}
+}
+
+func TestDialogueQuestFlow(t *testing.T) {
+	w := NewWorld()
+	player := newQuestTestPlayer(w, "npc-warden")
+
+	view, ok := w.PerformInteract(player.ID, "npc-warden")
+	if !ok {
+		t.Fatal("Interact failed")
+	}
+	ask := findChoice(view, "Any work for me? (Bones of the Weald)")
+	if ask < 0 {
+		t.Fatalf("Quest offer not shown: %+v", view.Choices)
+	}
+	if findChoice(view, "Take me to the Iron Weald.") >= 0 {
+		t.Error("Travel offered before the realm was restored")
+	}
+
+	res, ok := w.PerformDialogueChoice(player.ID, "npc-warden", view.NodeID, ask)
+	if !ok || res.View == nil {
+		t.Fatal("Choice failed")
+	}
+	res, ok = w.PerformDialogueChoice(player.ID, "npc-warden", res.View.NodeID, findChoice(res.View, "I'll do it."))
+	if !ok || !res.Quests || res.View != nil {
+		t.Fatalf("Accept via dialogue failed: %+v", res)
+	}
+	if player.activeQuest("bones_of_the_weald") < 0 {
+		t.Fatal("Quest not accepted")
+	}
+
+	// Offer is hidden now and picking it directly is rejected
+	if _, ok := w.PerformDialogueChoice(player.ID, "npc-warden", "start", ask); ok {
+		t.Error("Hidden choice was accepted")
+	}
+
+	player.X, player.Z = 300, 300
+	if _, ok := w.PerformInteract(player.ID, "npc-warden"); ok {
+		t.Error("Interacted from out of range")
+	}
+}
+
+func TestLoreDialogueRestorationAndTeleport(t *testing.T) {
+	w := NewWorld()
+	player := newQuestTestPlayer(w, "npc-stonewarden")
+
+	view, _ := w.PerformInteract(player.ID, "npc-stonewarden")
+	if findChoice(view, "How can this realm be restored?") < 0 || findChoice(view, "The realm feels different now.") >= 0 {
+		t.Fatalf("Unexpected choices before restoration: %+v", view.Choices)
+	}
+
+	for i := 0; i < RegionRestoreKills; i++ {
+		w.recordRegionKill(&Entity{X: 0, Z: -80})
+	}
+	if !w.RestoredRegions["Iron Weald"] {
+		t.Fatal("Region not restored")
+	}
+
+	view, _ = w.PerformInteract(player.ID, "npc-stonewarden")
+	if findChoice(view, "The realm feels different now.") < 0 {
+		t.Fatalf("Restored text not offered: %+v", view.Choices)
+	}
+
+	res, ok := w.PerformDialogueChoice(player.ID, "npc-stonewarden", "start", findChoice(view, "Take me back to town."))
+	if !ok || res.View != nil {
+		t.Fatal("Teleport failed")
+	}
+	if !InSafeZone(player.X, player.Z) {
+		t.Errorf("Teleport landed at %.0f,%.0f", player.X, player.Z)
+	}
+	if res.Teleport == nil || res.Teleport.X != player.X || res.Teleport.Z != player.Z || res.Teleport.Seq != 1 {
+		t.Fatalf("Teleport not reported to the client: %+v", res.Teleport)
+	}
+
+	// Moves sent before the client applied the teleport would undo it
+	x, z := player.X, player.Z
+	w.MovePlayer(player.ID, 0, 0, -80, 0, "MOVING", 0)
+	if player.X != x || player.Z != z {
+		t.Errorf("Stale move undid the teleport: %.0f,%.0f", player.X, player.Z)
+	}
+	w.MovePlayer(player.ID, x+1, 0, z, 0, "MOVING", res.Teleport.Seq)
+	if player.X != x+1 {
+		t.Error("Acknowledged moves should apply")
+	}
+
+	player.X, player.Z = w.Entities["npc-warden"].X, w.Entities["npc-warden"].Z
+	view, _ = w.PerformInteract(player.ID, "npc-warden")
+	if findChoice(view, "Take me to the Iron Weald.") < 0 {
+		t.Error("Travel to restored realm not offered")
+	}
+}
@@ -0,0 +1,208 @@
+package game
+
+import (
+	"math/rand"
+)
+
+const (
+	BaseMaxDurability   = 60
+	DurabilityPerLevel  = 2
+	DeathDurabilityLoss = 10   // % of max durability lost on every equipped item
+	CombatWearChance    = 0.05 // Per hit dealt (weapon) or taken (one armor piece)
+	RepairCostPerPoint  = 2    // Gold per missing point, scaled by item level
+)
+
+// Crafting materials from salvaging
+const (
+	MaterialScrap   = "scrap"
+	MaterialEssence = "essence"
+	MaterialCrystal = "crystal"
+	MaterialSoul    = "soul_shard"
+)
+
+// Material yielded by salvaging an item of each rarity (scrap is always included)
+var SalvageMaterial = map[ItemRarity]string{
+	RarityCommon:    MaterialScrap,
+	RarityUncommon:  MaterialEssence,
+	RarityRare:      MaterialCrystal,
+	RaritySet:       MaterialCrystal,
+	RarityLegendary: MaterialSoul,
+}
+
+// maxDurability for a freshly generated item of this level.
+func maxDurability(level int) int {
+	return BaseMaxDurability + level*DurabilityPerLevel
+}
+
+// Broken items stay equipped but contribute nothing.
+func (item *Item) Broken() bool {
+	return item.MaxDurability > 0 && item.Durability <= 0
+}
+
+// wear reduces durability. Returns true if the item just broke.
+func (item *Item) wear(amount int) bool {
+	if item.MaxDurability <= 0 || item.Durability <= 0 {
+		return false
+	}
+	item.Durability -= amount
+	if item.Durability <= 0 {
+		item.Durability = 0
+		return true
+	}
+	return false
+}
+
+// RepairCost is the gold needed to restore an item to full durability.
+func RepairCost(item Item) int {
+	missing := item.MaxDurability - item.Durability
+	if missing <= 0 {
+		return 0
+	}
+	return missing * RepairCostPerPoint * (1 + item.Level/5)
+}
+
+// wearSlot damages one equipped item, recalculating stats if it broke.
+func (e *Entity) wearSlot(slot string, amount int) {
+	item, ok := e.Equipment[slot]
+	if !ok {
+		return
+	}
+	broke := item.wear(amount)
+	e.Equipment[slot] = item
+	if broke {
+		e.RecalculateStats()
+	}
+}
+
+// wearWeapon may chip the weapon after landing a hit.
+func (e *Entity) wearWeapon() {
+	if e.Type != TypePlayer || rand.Float64() >= CombatWearChance {
+		return
+	}
+	e.wearSlot("mainHand", 1)
+}
+
+// wearArmor may chip a random armor piece after being hit.
+func (e *Entity) wearArmor() {
+	if e.Type != TypePlayer || len(e.Equipment) == 0 || rand.Float64() >= CombatWearChance {
+		return
+	}
+	var slots []string
+	for slot := range e.Equipment {
+		if slot != "mainHand" {
+			slots = append(slots, slot)
+		}
+	}
+	if len(slots) == 0 {
+		return
+	}
+	e.wearSlot(slots[rand.Intn(len(slots))], 1)
+}
+
+// applyDeathDurability takes a share of max durability from all equipped items.
+func (e *Entity) applyDeathDurability() {
+	if e.Type != TypePlayer {
+		return
+	}
+	broke := false
+	for slot, item := range e.Equipment {
+		loss := item.MaxDurability * DeathDurabilityLoss / 100
+		if loss < 1 {
+			loss = 1
+		}
+		if item.wear(loss) {
+			broke = true
+		}
+		e.Equipment[slot] = item
+	}
+	if broke {
+		e.RecalculateStats()
+	}
+}
+
+// PerformRepair repairs one item (inventory or equipped) at the merchant, or
+// every item the player carries when itemID is empty.
+func (w *World) PerformRepair(playerID, itemID string) (*Entity, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	player, ok := w.Entities[playerID]
+	if !ok || player.State == "DEAD" || !w.nearMerchant(player) {
+		return nil, false
+	}
+
+	if itemID != "" {
+		item, slot, index := player.findGearItem(itemID)
+		if item == nil {
+			return nil, false
+		}
+		cost := RepairCost(*item)
+		if cost == 0 || player.Gold < cost {
+			return nil, false
+		}
+		player.Gold -= cost
+		item.Durability = item.MaxDurability
+		player.storeGearItem(item, slot, index)
+		return player, true
+	}
+
+	total := 0
+	for _, item := range player.Equipment {
+		total += RepairCost(item)
+	}
+	for _, item := range player.Inventory {
+		total += RepairCost(item)
+	}
+	if total == 0 || player.Gold < total {
+		return nil, false
+	}
+	player.Gold -= total
+	for slot, item := range player.Equipment {
+		item.Durability = item.MaxDurability
+		player.Equipment[slot] = item
+	}
+	for i := range player.Inventory {
+		player.Inventory[i].Durability = player.Inventory[i].MaxDurability
+	}
+	player.RecalculateStats()
+	return player, true
+}
+
+// SalvageYield returns the materials an item breaks down into.
+func SalvageYield(item Item) map[string]int {
+	yield := map[string]int{MaterialScrap: 1 + item.Level/5}
+	if mat, ok := SalvageMaterial[item.Rarity]; ok && mat != MaterialScrap {
+		yield[mat] += 1 + item.Level/10
+	}
+	return yield
+}
+
+// PerformSalvage destroys an inventory item at the merchant in exchange for
+// crafting materials. Items with socketed gems must be emptied first.
+func (w *World) PerformSalvage(playerID, itemID string) (*Entity, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	player, ok := w.Entities[playerID]
+	if !ok || player.State == "DEAD" || !w.nearMerchant(player) {
+		return nil, false
+	}
+
+	idx := player.findInventoryItem(itemID)
+	if idx < 0 {
+		return nil, false
+	}
+	item := player.Inventory[idx]
+	if item.Type == ItemGem || len(item.Gems) > 0 {
+		return nil, false
+	}
+
+	player.removeInventoryItem(itemID)
+	if player.Materials == nil {
+		player.Materials = make(map[string]int)
+	}
+	for mat, n := range SalvageYield(item) {
+		player.Materials[mat] += n
+	}
+	return player, true
+}
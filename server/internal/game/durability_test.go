@@ -0,0 +1,91 @@
+package game
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestBrokenItemGivesNoStats(t *testing.T) {
+	sword := createItem(BaseItem{"Iron Sword", ItemWeapon, "mainHand", "damage", 10, "strength"}, RarityCommon, 1.0, 0, 5)
+	player := &Entity{
+		ID:        "p1",
+		Type:      TypePlayer,
+		Level:     5,
+		BaseStats: Stats{Strength: 10, Vitality: 10},
+		Equipment: map[string]Item{"mainHand": *sword},
+	}
+	player.RecalculateStats()
+	withSword := player.Damage
+
+	player.wearSlot("mainHand", sword.MaxDurability)
+	broken := player.Equipment["mainHand"]
+	if !broken.Broken() {
+		t.Fatal("Sword should be broken")
+	}
+	if data, _ := json.Marshal(broken); !strings.Contains(string(data), `"durability":0`) {
+		t.Errorf("Broken item should still send its durability: %s", data)
+	}
+	if player.Damage != withSword-sword.Stats[StatDamage] {
+		t.Errorf("Damage = %d with broken sword, want %d", player.Damage, withSword-sword.Stats[StatDamage])
+	}
+}
+
+func TestDeathDurabilityAndRepair(t *testing.T) {
+	w := NewWorld()
+	merchant := w.Entities[MerchantID]
+
+	helm := createItem(BaseItem{"Iron Helm", ItemArmor, "head", "defense", 4, ""}, RarityCommon, 1.0, 0, 10)
+	player := &Entity{
+		ID:        "p1",
+		Type:      TypePlayer,
+		X:         merchant.X,
+		Z:         merchant.Z,
+		Equipment: map[string]Item{"head": *helm},
+	}
+	w.Entities[player.ID] = player
+
+	player.applyDeathDurability()
+	worn := player.Equipment["head"]
+	want := helm.MaxDurability - helm.MaxDurability*DeathDurabilityLoss/100
+	if worn.Durability != want {
+		t.Fatalf("Durability = %d after death, want %d", worn.Durability, want)
+	}
+
+	cost := RepairCost(worn)
+	player.Gold = cost - 1
+	if _, ok := w.PerformRepair(player.ID, ""); ok {
+		t.Fatal("Repaired without enough gold")
+	}
+	player.Gold = cost
+	if _, ok := w.PerformRepair(player.ID, helm.ID); !ok {
+		t.Fatal("Repair failed")
+	}
+	if player.Equipment["head"].Durability != helm.MaxDurability || player.Gold != 0 {
+		t.Errorf("After repair: durability %d, gold %d", player.Equipment["head"].Durability, player.Gold)
+	}
+}
+
+func TestSalvage(t *testing.T) {
+	w := NewWorld()
+	merchant := w.Entities[MerchantID]
+
+	item := createItem(BaseItem{"Robes", ItemArmor, "chest", "defense", 3, ""}, RarityRare, 3.0, 2, 10)
+	player := &Entity{ID: "p1", Type: TypePlayer, X: merchant.X, Z: merchant.Z, Inventory: []Item{*item}}
+	w.Entities[player.ID] = player
+
+	if _, ok := w.PerformSalvage(player.ID, item.ID); !ok {
+		t.Fatal("Salvage failed")
+	}
+	if len(player.Inventory) != 0 {
+		t.Error("Item still in inventory after salvage")
+	}
+	for mat, n := range SalvageYield(*item) {
+		if player.Materials[mat] != n {
+			t.Errorf("Materials[%s] = %d, want %d", mat, player.Materials[mat], n)
+		}
+	}
+	if player.Materials[MaterialCrystal] == 0 {
+		t.Error("Rare salvage yielded no crystals")
+	}
+}
@@ -0,0 +1,98 @@
+package game
+
+const EnchantBaseCost = 200
+
+// EnchantCost returns the gold and materials needed to reroll one affix.
+func EnchantCost(item Item) (int, map[string]int) {
+	mult, ok := RarityPriceMultiplier[item.Rarity]
+	if !ok {
+		mult = 1
+	}
+	gold := EnchantBaseCost * (1 + item.Level/5) * mult
+
+	mats := map[string]int{MaterialScrap: 2 + item.Level/5}
+	if mat, ok := SalvageMaterial[item.Rarity]; ok && mat != MaterialScrap {
+		mats[mat] += 1 + item.Level/10
+	}
+	return gold, mats
+}
+
+// enchantableAffix reports whether the affix at index may be rerolled. Once an
+// item has been enchanted only that affix can be enchanted again.
+func (item *Item) enchantableAffix(index int) bool {
+	if index < 0 || index >= len(item.Affixes) {
+		return false
+	}
+	for i, a := range item.Affixes {
+		if a.Enchanted && i != index {
+			return false
+		}
+	}
+	return true
+}
+
+// rerollAffix replaces one affix with a fresh roll of the same kind, never
+// duplicating another affix already on the item.
+func (item *Item) rerollAffix(index int) bool {
+	old := item.Affixes[index]
+	exclude := make(map[string]bool)
+	for i, a := range item.Affixes {
+		if i != index {
+			exclude[a.ID] = true
+		}
+	}
+
+	def := pickWeightedAffix(eligibleAffixes(old.Kind, item.Slot, item.Level, exclude))
+	if def == nil {
+		return false
+	}
+	affix := rollAffixValue(def, item.Level, item.Rarity)
+	affix.Enchanted = true
+
+	// Copy so earlier snapshots of the item keep their affixes
+	affixes := append([]ItemAffix{}, item.Affixes...)
+	affixes[index] = affix
+	item.Affixes = affixes
+
+	item.RebuildStats()
+	item.RebuildName()
+	item.RebuildDescription()
+	return true
+}
+
+// PerformEnchant rerolls the chosen affix of an inventory or equipped item at
+// the merchant for gold and crafting materials.
+func (w *World) PerformEnchant(playerID, itemID string, affix int) (*Entity, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	player, ok := w.Entities[playerID]
+	if !ok || player.State == "DEAD" || !w.nearMerchant(player) {
+		return nil, false
+	}
+
+	item, slot, index := player.findGearItem(itemID)
+	if item == nil || !item.enchantableAffix(affix) {
+		return nil, false
+	}
+
+	gold, mats := EnchantCost(*item)
+	if player.Gold < gold {
+		return nil, false
+	}
+	for mat, n := range mats {
+		if player.Materials[mat] < n {
+			return nil, false
+		}
+	}
+
+	if !item.rerollAffix(affix) {
+		return nil, false
+	}
+	player.Gold -= gold
+	for mat, n := range mats {
+		player.Materials[mat] -= n
+	}
+	player.storeGearItem(item, slot, index)
+	return player, true
+}
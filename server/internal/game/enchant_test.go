@@ -0,0 +1,57 @@
+package game
+
+import (
+	"testing"
+)
+
+func TestEnchantRerollsOnlyChosenAffix(t *testing.T) {
+	w := NewWorld()
+	merchant := w.Entities[MerchantID]
+
+	item := createItem(BaseItem{"Iron Helm", ItemArmor, "head", "defense", 4, ""}, RarityRare, 3.0, 2, 12)
+	gold, mats := EnchantCost(*item)
+	player := &Entity{
+		ID:        "p1",
+		Type:      TypePlayer,
+		X:         merchant.X,
+		Z:         merchant.Z,
+		Gold:      gold * 2,
+		Materials: map[string]int{},
+		Inventory: []Item{*item},
+	}
+	for mat, n := range mats {
+		player.Materials[mat] = n * 2
+	}
+	w.Entities[player.ID] = player
+
+	kept := item.Affixes[1]
+	if _, ok := w.PerformEnchant(player.ID, item.ID, 0); !ok {
+		t.Fatal("Enchant failed")
+	}
+	enchanted := player.Inventory[0]
+	if !enchanted.Affixes[0].Enchanted || enchanted.Affixes[0].Kind != item.Affixes[0].Kind {
+		t.Fatalf("Affix 0 not rerolled as same kind: %+v", enchanted.Affixes[0])
+	}
+	if enchanted.Affixes[1] != kept {
+		t.Fatalf("Untouched affix changed: %+v -> %+v", kept, enchanted.Affixes[1])
+	}
+	if enchanted.Affixes[0].ID == kept.ID {
+		t.Fatal("Reroll duplicated another affix")
+	}
+	if player.Gold != gold {
+		t.Errorf("Gold = %d, want %d", player.Gold, gold)
+	}
+
+	// Only the enchanted affix can be rerolled again
+	if _, ok := w.PerformEnchant(player.ID, item.ID, 1); ok {
+		t.Fatal("Enchanted a second affix")
+	}
+	if _, ok := w.PerformEnchant(player.ID, item.ID, 0); !ok {
+		t.Fatal("Could not re-enchant the enchanted affix")
+	}
+
+	// Out of gold and materials
+	if _, ok := w.PerformEnchant(player.ID, item.ID, 0); ok {
+		t.Fatal("Enchanted without paying")
+	}
+}
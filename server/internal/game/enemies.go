@@ -0,0 +1,172 @@
+package game
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// AttackStyle is how an enemy subtype fights
+type AttackStyle string
+
+const (
+	AttackMelee  AttackStyle = "melee"  // Weapon hits at close range
+	AttackRanged AttackStyle = "ranged" // Fast single-target bolts
+	AttackCaster AttackStyle = "caster" // Slow bolts that burst on impact
+)
+
+const (
+	MeleeRange         = 2.5
+	CasterSplashShare  = 0.5 // Damage share dealt to other players in a caster burst
+	projectileLifetime = 1.5 // Bolts fly this many times their attack range before fizzling
+)
+
+// EnemyProfile is the elemental identity and attack profile of an enemy subtype
+type EnemyProfile struct {
+	Attack DamageType
+	Resist Resistances // Negative values are weaknesses
+
+	Style            AttackStyle
+	Range            float64 // Distance the enemy attacks from
+	Projectile       string  // Projectile subtype for ranged and caster attacks
+	ProjectileSpeed  float64
+	ProjectileRadius float64
+	Splash           float64 // Caster bursts hit other players within this radius
+
+	AI     AIKind
+	FleeAt float64 // Health fraction that makes it flee; 0 never flees
+}
+
+// Each realm's creatures strike with its element, resist it, and are weak to
+// the element that overcomes it (Water > Fire > Air > Earth > Water).
+var EnemyProfiles = map[string]EnemyProfile{
+	"Skeleton": {Attack: DamageEarth, Resist: Resistances{Earth: 40, Air: -25},
+		Style: AttackMelee, Range: MeleeRange, AI: AISwarm},
+	"Imp": {Attack: DamageFire, Resist: Resistances{Fire: 50, Water: -25},
+		Style: AttackRanged, Range: 18, Projectile: "FireBolt", ProjectileSpeed: 18, ProjectileRadius: 0.8,
+		AI: AIKite, FleeAt: 0.3},
+	"DemonOrc": {Attack: DamageWater, Resist: Resistances{Water: 40, Earth: -25},
+		Style: AttackMelee, Range: MeleeRange, AI: AICharge, FleeAt: 0.15},
+	"Construct": {Attack: DamageAir, Resist: Resistances{Air: 40, Fire: -25},
+		Style: AttackCaster, Range: 22, Projectile: "ArcOrb", ProjectileSpeed: 12, ProjectileRadius: 1.2, Splash: 4,
+		AI: AIGuard},
+}
+
+// GetEnemyProfile returns a subtype's profile. Unknown subtypes are physical melee.
+func GetEnemyProfile(subType string) EnemyProfile {
+	p, ok := EnemyProfiles[subType]
+	if !ok {
+		p = EnemyProfile{Attack: DamagePhysical}
+	}
+	if p.Style == "" {
+		p.Style = AttackMelee
+	}
+	if p.Range <= 0 || p.Projectile == "" {
+		// Without a projectile every style falls back to melee
+		p.Style, p.Range = AttackMelee, MeleeRange
+	}
+	return p
+}
+
+// applyEnemyProfile sets an enemy's combat chances, attack element and
+// resistances from its subtype.
+func (e *Entity) applyEnemyProfile() {
+	e.CritChance, e.DodgeChance, e.BlockChance = combatChances(e.BaseStats, 0, false)
+	e.Element = DamagePhysical
+	if p, ok := EnemyProfiles[e.SubType]; ok {
+		e.Element = p.Attack
+		e.Resistances = p.Resist
+	}
+}
+
+// enemyAttack makes an enemy attack its target in its subtype's style.
+// Caller must hold the lock.
+func (w *World) enemyAttack(e, target *Entity, profile EnemyProfile) {
+	if profile.Style == AttackMelee {
+		w.weaponHit(e, target)
+		return
+	}
+	w.fireEnemyProjectile(e, target, profile)
+}
+
+// fireEnemyProjectile launches a bolt at where the target stands now; moving
+// out of its path dodges it. Caller must hold the lock.
+func (w *World) fireEnemyProjectile(e, target *Entity, profile EnemyProfile) *Entity {
+	dx := target.X - e.X
+	dz := target.Z - e.Z
+	dist := math.Sqrt(dx*dx + dz*dz)
+	if dist == 0 {
+		dist = 1
+	}
+	velX := (dx / dist) * profile.ProjectileSpeed
+	velZ := (dz / dist) * profile.ProjectileSpeed
+	flight := profile.Range / profile.ProjectileSpeed * projectileLifetime
+
+	proj := &Entity{
+		ID:         fmt.Sprintf("proj-%s-%d", e.ID, time.Now().UnixNano()),
+		Type:       TypeProjectile,
+		SubType:    profile.Projectile,
+		X:          e.X,
+		Y:          1.5,
+		Z:          e.Z,
+		VelX:       velX,
+		VelZ:       velZ,
+		Radius:     profile.ProjectileRadius,
+		Damage:     e.Damage,
+		OwnerID:    e.ID,
+		Rotation:   math.Atan2(velX, velZ),
+		Element:    e.Element,
+		Hostile:    true,
+		Splash:     profile.Splash,
+		ExpireTime: w.now().Add(time.Duration(flight * float64(time.Second))),
+	}
+	w.Entities[proj.ID] = proj
+	e.Rotation = proj.Rotation
+	return proj
+}
+
+// updateHostileProjectile checks an enemy bolt against players. Bolts fizzle
+// at the edge of the safe zone. Returns false once the bolt is spent.
+// Caller must hold the lock.
+func (w *World) updateHostileProjectile(proj *Entity, players []*Entity) bool {
+	if InSafeZone(proj.X, proj.Z) || w.now().After(proj.ExpireTime) {
+		return false
+	}
+	owner := w.Entities[proj.OwnerID]
+	for _, target := range players {
+		if target.State == "DEAD" {
+			continue
+		}
+		dx := proj.X - target.X
+		dz := proj.Z - target.Z
+		if math.Sqrt(dx*dx+dz*dz) >= proj.Radius+0.5 { // 0.5 is approx player radius
+			continue
+		}
+
+		w.ApplyDamage(owner, target, DamageSpec{Amount: boltDamage(proj.Damage, target), Type: proj.Element, Source: proj.SubType})
+		if proj.Splash > 0 {
+			splash := int(float64(proj.Damage) * CasterSplashShare)
+			for _, other := range players {
+				if other == target || other.State == "DEAD" || InSafeZone(other.X, other.Z) {
+					continue
+				}
+				sdx := proj.X - other.X
+				sdz := proj.Z - other.Z
+				if math.Sqrt(sdx*sdx+sdz*sdz) < proj.Splash {
+					w.ApplyDamage(owner, other, DamageSpec{Amount: boltDamage(splash, other), Type: proj.Element, Source: proj.SubType})
+				}
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// boltDamage reduces an enemy bolt by the player's Defense, like a weapon hit.
+func boltDamage(amount int, target *Entity) int {
+	damage := amount - target.Defense
+	if damage < 1 {
+		damage = 1
+	}
+	return damage
+}
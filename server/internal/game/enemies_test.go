@@ -0,0 +1,129 @@
+package game
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEnemyAttackProfiles(t *testing.T) {
+	if p := GetEnemyProfile("Skeleton"); p.Style != AttackMelee || p.Range != MeleeRange {
+		t.Errorf("Skeleton = %s at %.1f, want melee", p.Style, p.Range)
+	}
+	if p := GetEnemyProfile("Imp"); p.Style != AttackRanged || p.Projectile == "" || p.Range <= MeleeRange {
+		t.Errorf("Imp = %+v, want ranged bolts", p)
+	}
+	if p := GetEnemyProfile("Construct"); p.Style != AttackCaster || p.Splash <= 0 {
+		t.Errorf("Construct = %+v, want caster with splash", p)
+	}
+	if p := GetEnemyProfile("Mystery"); p.Style != AttackMelee || p.Attack != DamagePhysical {
+		t.Errorf("Unknown subtype = %+v, want physical melee", p)
+	}
+}
+
+// rangedSetup places an Imp 10 units from a player in the Shifting Sands.
+func rangedSetup() (*World, *Entity, *Entity) {
+	w := NewWorld()
+	w.Entities = map[string]*Entity{}
+
+	imp := &Entity{ID: "Imp-x", Type: TypeEnemy, SubType: "Imp", Level: 10, Health: 100, MaxHealth: 100, Damage: 30, X: 200, Z: 0, SpawnX: 200, Speed: 4, AttackCooldown: time.Hour}
+	imp.applyEnemyProfile()
+	player := &Entity{ID: "p1", Type: TypePlayer, Health: 500, MaxHealth: 500, Defense: 10, X: 210, Z: 0}
+	w.Entities[imp.ID] = imp
+	w.Entities[player.ID] = player
+	return w, imp, player
+}
+
+func projectiles(w *World) []*Entity {
+	var out []*Entity
+	for _, e := range w.Entities {
+		if e.Type == TypeProjectile {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func TestImpFiresBoltFromRange(t *testing.T) {
+	w, imp, player := rangedSetup()
+
+	w.Update(0.05)
+
+	bolts := projectiles(w)
+	if len(bolts) != 1 {
+		t.Fatalf("Projectiles = %d, want 1", len(bolts))
+	}
+	bolt := bolts[0]
+	if !bolt.Hostile || bolt.OwnerID != imp.ID || bolt.SubType != "FireBolt" || bolt.Element != DamageFire {
+		t.Errorf("Bolt = %+v", bolt)
+	}
+	if imp.X != 200 {
+		t.Errorf("Ranged enemy should hold position in range, moved to %.2f", imp.X)
+	}
+
+	// Fly until it connects; Defense applies, fire is the Imp's element
+	for i := 0; i < 20 && len(projectiles(w)) > 0; i++ {
+		w.Update(0.05)
+	}
+	if len(projectiles(w)) != 0 {
+		t.Fatal("Bolt should be spent after hitting")
+	}
+	if player.Health != 500-(30-10) {
+		t.Errorf("Player health = %d, want %d", player.Health, 500-(30-10))
+	}
+
+	hit := false
+	for _, ev := range w.DrainEvents() {
+		if ev.Type == EventHit && ev.TargetID == player.ID && ev.Source == "FireBolt" && ev.DamageType == DamageFire {
+			hit = true
+		}
+	}
+	if !hit {
+		t.Error("Expected a FireBolt hit event")
+	}
+}
+
+func TestBoltDodgedByMoving(t *testing.T) {
+	w, imp, player := rangedSetup()
+	bolt := w.fireEnemyProjectile(imp, player, GetEnemyProfile(imp.SubType))
+	imp.LastAttackTime = time.Now()
+
+	// Step aside before it arrives; the bolt keeps flying where they stood
+	player.Z = 5
+	for i := 0; i < 20; i++ {
+		w.Update(0.05)
+	}
+	if player.Health != 500 {
+		t.Errorf("Player health = %d, dodged bolt should miss", player.Health)
+	}
+	if _, ok := w.Entities[bolt.ID]; !ok || bolt.X <= player.X {
+		t.Errorf("Bolt should fly past the player, at %.1f", bolt.X)
+	}
+
+	// Bolts fizzle once their flight time runs out
+	bolt.ExpireTime = time.Now().Add(-time.Millisecond)
+	w.Update(0.05)
+	if _, ok := w.Entities[bolt.ID]; ok {
+		t.Error("Expired bolt should be removed")
+	}
+}
+
+func TestCasterBurstSplashesAndSafeZoneBlocks(t *testing.T) {
+	w := NewWorld()
+	w.Entities = map[string]*Entity{}
+
+	a := &Entity{ID: "a", Type: TypePlayer, Health: 100, MaxHealth: 100, X: 400, Z: 0}
+	b := &Entity{ID: "b", Type: TypePlayer, Health: 100, MaxHealth: 100, X: 402, Z: 0}
+	w.Entities[a.ID] = a
+	w.Entities[b.ID] = b
+	w.Entities["orb"] = &Entity{ID: "orb", Type: TypeProjectile, SubType: "ArcOrb", X: 400, Z: 0, Radius: 1.2, Damage: 40, Element: DamageAir, Hostile: true, Splash: 4, ExpireTime: time.Now().Add(time.Second)}
+	w.Entities["town"] = &Entity{ID: "town", Type: TypeProjectile, SubType: "FireBolt", X: 10, Z: 0, Radius: 1, Damage: 40, Hostile: true, ExpireTime: time.Now().Add(time.Second)}
+
+	w.Update(0.01)
+
+	if a.Health != 60 || b.Health != 80 {
+		t.Errorf("Health a=%d b=%d, want 60 and 80", a.Health, b.Health)
+	}
+	if len(projectiles(w)) != 0 {
+		t.Error("Orb should burst and the town bolt should fizzle")
+	}
+}
@@ -0,0 +1,132 @@
+package game
+
+type CombatEventType string
+
+const (
+	EventHit       CombatEventType = "hit" // Includes misses, dodges and blocks (see Outcome)
+	EventCrit      CombatEventType = "crit"
+	EventHeal      CombatEventType = "heal"
+	EventShield    CombatEventType = "shield" // Amount is the new absorb total
+	EventDeath     CombatEventType = "death"
+	EventXPGain    CombatEventType = "xp_gain"
+	EventLevelUp   CombatEventType = "level_up"
+	EventLootDrop  CombatEventType = "loot_drop"
+	EventCast      CombatEventType = "cast" // Amount is the cast time in ms
+	EventInterrupt CombatEventType = "interrupt"
+)
+
+// Events beyond this are dropped (oldest first) if nobody drains the queue
+const MaxQueuedEvents = 2048
+
+// CombatEvent is one entry of the combat stream sent to nearby clients
+type CombatEvent struct {
+	Type       CombatEventType `json:"type"`
+	SourceID   string          `json:"sourceId,omitempty"`
+	TargetID   string          `json:"targetId"`
+	Amount     int             `json:"amount,omitempty"` // Damage, healing, XP or cast time
+	Absorbed   int             `json:"absorbed,omitempty"`
+	DamageType DamageType      `json:"damageType,omitempty"`
+	Outcome    HitOutcome      `json:"outcome,omitempty"`
+	Source     string          `json:"source,omitempty"` // Ability or effect name
+	Level      int             `json:"level,omitempty"`  // New level for level_up
+	Item       string          `json:"item,omitempty"`   // Loot name for loot_drop
+	Rarity     ItemRarity      `json:"rarity,omitempty"`
+	X          float64         `json:"x"` // Where it happened, for view distance
+	Z          float64         `json:"z"`
+}
+
+// queueEvent appends to the combat stream. Caller must hold the lock.
+func (w *World) queueEvent(ev CombatEvent) {
+	if len(w.events) >= MaxQueuedEvents {
+		w.events = w.events[1:]
+	}
+	w.events = append(w.events, ev)
+}
+
+// DrainEvents returns and clears the queued combat events. Called by the hub once per tick.
+func (w *World) DrainEvents() []CombatEvent {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	events := w.events
+	w.events = nil
+	return events
+}
+
+// EventsForPlayer keeps the events a player can see: anything within view
+// distance plus everything they caused or received.
+func (w *World) EventsForPlayer(events []CombatEvent, playerID string, viewDistance float64) []CombatEvent {
+	w.mu.RLock()
+	player, ok := w.Entities[playerID]
+	var px, pz float64
+	if ok {
+		px, pz = player.X, player.Z
+	}
+	w.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	var out []CombatEvent
+	for _, ev := range events {
+		dx := ev.X - px
+		dz := ev.Z - pz
+		if dx*dx+dz*dz <= viewDistance*viewDistance || ev.SourceID == playerID || ev.TargetID == playerID {
+			out = append(out, ev)
+		}
+	}
+	return out
+}
+
+// emitDamage adds a damage result to the stream, including misses that never
+// reach ApplyDamage. Caller must hold the lock.
+func (w *World) emitDamage(ev DamageEvent, target *Entity) {
+	kind := EventHit
+	if ev.Crit {
+		kind = EventCrit
+	}
+	w.queueEvent(CombatEvent{
+		Type:       kind,
+		SourceID:   ev.SourceID,
+		TargetID:   ev.TargetID,
+		Amount:     ev.Amount,
+		Absorbed:   ev.Absorbed,
+		DamageType: ev.Type,
+		Outcome:    ev.Outcome,
+		Source:     ev.Source,
+		X:          target.X,
+		Z:          target.Z,
+	})
+}
+
+// heal restores health, clamped to the maximum, and reports it. Returns the
+// amount actually healed. Caller must hold the lock.
+func (w *World) heal(source, target *Entity, amount int, name string) int {
+	if amount <= 0 || target.State == "DEAD" {
+		return 0
+	}
+	if target.Health+amount > target.MaxHealth {
+		amount = target.MaxHealth - target.Health
+	}
+	if amount <= 0 {
+		return 0
+	}
+	target.Health += amount
+
+	ev := CombatEvent{Type: EventHeal, TargetID: target.ID, Amount: amount, Source: name, X: target.X, Z: target.Z}
+	if source != nil {
+		ev.SourceID = source.ID
+	}
+	w.queueEvent(ev)
+	return amount
+}
+
+// awardExperience grants XP and reports the gain and any level ups. Caller must hold the lock.
+func (w *World) awardExperience(player *Entity, amount int, source string) {
+	levels := player.grantExperience(amount)
+	w.queueEvent(CombatEvent{Type: EventXPGain, TargetID: player.ID, Amount: amount, Source: source, X: player.X, Z: player.Z})
+	if levels > 0 {
+		w.queueEvent(CombatEvent{Type: EventLevelUp, TargetID: player.ID, Level: player.Level, X: player.X, Z: player.Z})
+	}
+	w.recordLevel(player)
+}
@@ -0,0 +1,65 @@
+package game
+
+import (
+	"testing"
+)
+
+func TestEventQueueDrainsAndCaps(t *testing.T) {
+	w := NewWorld()
+	w.DrainEvents()
+
+	for i := 0; i < MaxQueuedEvents+10; i++ {
+		w.queueEvent(CombatEvent{Type: EventHit, Amount: i})
+	}
+	events := w.DrainEvents()
+	if len(events) != MaxQueuedEvents || events[0].Amount != 10 {
+		t.Fatalf("Queue kept %d events starting at %d", len(events), events[0].Amount)
+	}
+	if len(w.DrainEvents()) != 0 {
+		t.Error("Drain did not clear the queue")
+	}
+}
+
+func TestEventsFilteredByViewDistance(t *testing.T) {
+	w := NewWorld()
+	w.Entities["p1"] = &Entity{ID: "p1", Type: TypePlayer, X: 100, Z: 100}
+
+	events := []CombatEvent{
+		{Type: EventHit, TargetID: "near", X: 110, Z: 100},
+		{Type: EventHit, TargetID: "far", X: 400, Z: 400},
+		{Type: EventXPGain, TargetID: "p1", X: 400, Z: 400},
+	}
+	got := w.EventsForPlayer(events, "p1", 60)
+	if len(got) != 2 || got[0].TargetID != "near" || got[1].TargetID != "p1" {
+		t.Errorf("Filtered = %+v", got)
+	}
+	if w.EventsForPlayer(events, "missing", 60) != nil {
+		t.Error("Unknown player received events")
+	}
+}
+
+func TestKillEmitsXPLevelAndLootEvents(t *testing.T) {
+	w := NewWorld()
+	w.DrainEvents()
+
+	player := &Entity{ID: "p1", Type: TypePlayer, SubType: "Fighter", Level: 1, MaxExperience: 10, Health: 100, MaxHealth: 100, X: 100}
+	elite := &Entity{ID: "elite-Imp-1", Type: TypeEnemy, SubType: "Imp", Level: 5, Health: 1, MaxHealth: 1, X: 101}
+	w.Entities[player.ID] = player
+	w.Entities[elite.ID] = elite
+
+	w.ApplyDamage(player, elite, DamageSpec{Amount: 10, Source: "Dagger"})
+
+	counts := map[CombatEventType]int{}
+	for _, ev := range w.DrainEvents() {
+		counts[ev.Type]++
+		if ev.Type == EventLootDrop && (ev.Item == "" || ev.SourceID != "p1") {
+			t.Errorf("Bad loot event %+v", ev)
+		}
+	}
+	if counts[EventHit] != 1 || counts[EventDeath] != 1 || counts[EventXPGain] != 1 || counts[EventLevelUp] != 1 {
+		t.Errorf("Event counts = %v", counts)
+	}
+	if counts[EventLootDrop] < EliteLootTable.Guaranteed {
+		t.Errorf("Loot drop events = %d, want at least %d", counts[EventLootDrop], EliteLootTable.Guaranteed)
+	}
+}
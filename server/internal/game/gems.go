@@ -0,0 +1,232 @@
+package game
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+const ItemGem ItemType = "GEM"
+
+const (
+	MaxGemTier     = 5
+	MaxDropGemTier = 3 // Higher tiers only come from combining
+	GemsToCombine  = 3
+)
+
+// Min/Max sockets rolled per rarity
+var SocketRange = map[ItemRarity][2]int{
+	RarityCommon:    {0, 0},
+	RarityUncommon:  {0, 1},
+	RarityRare:      {0, 2},
+	RaritySet:       {1, 2},
+	RarityLegendary: {1, 3},
+}
+
+type GemDef struct {
+	ID   string
+	Name string
+	Stat string
+}
+
+var GemTypes = []GemDef{
+	{ID: "ruby", Name: "Ruby", Stat: "strength"},
+	{ID: "emerald", Name: "Emerald", Stat: "dexterity"},
+	{ID: "sapphire", Name: "Sapphire", Stat: "intelligence"},
+	{ID: "topaz", Name: "Topaz", Stat: "wisdom"},
+	{ID: "amethyst", Name: "Amethyst", Stat: "vitality"},
+}
+
+// Per tier (index = tier-1)
+var (
+	GemTierNames  = []string{"Chipped", "Flawed", "", "Flawless", "Perfect"}
+	GemTierValues = []int{2, 4, 7, 12, 20}
+	// Gold the merchant charges to combine three gems of a tier into the next
+	GemCombineCost = []int{100, 400, 1500, 5000}
+)
+
+func GetGemType(id string) *GemDef {
+	for i := range GemTypes {
+		if GemTypes[i].ID == id {
+			return &GemTypes[i]
+		}
+	}
+	return nil
+}
+
+// rollSockets picks a socket count for a freshly generated item.
+func rollSockets(rarity ItemRarity) int {
+	r, ok := SocketRange[rarity]
+	if !ok || r[1] <= 0 {
+		return 0
+	}
+	return r[0] + rand.Intn(r[1]-r[0]+1)
+}
+
+// NewGem creates a gem item of the given type and tier, or nil if invalid.
+func NewGem(gemType string, tier int) *Item {
+	def := GetGemType(gemType)
+	if def == nil || tier < 1 || tier > MaxGemTier {
+		return nil
+	}
+	name := def.Name
+	if prefix := GemTierNames[tier-1]; prefix != "" {
+		name = prefix + " " + name
+	}
+	value := GemTierValues[tier-1]
+	return &Item{
+		ID:          fmt.Sprintf("item-%d", rand.Int63()),
+		Name:        name,
+		Type:        ItemGem,
+		Rarity:      RarityCommon,
+		Level:       1,
+		Stats:       map[string]int{def.Stat: value},
+		Value:       tier * tier * 20,
+		Description: fmt.Sprintf("+%d %s when socketed", value, def.Stat),
+		GemType:     def.ID,
+		GemTier:     tier,
+	}
+}
+
+// GenerateGem rolls a random gem for a drop at the given enemy level.
+func GenerateGem(level int) *Item {
+	maxTier := 1 + level/8
+	if maxTier > MaxDropGemTier {
+		maxTier = MaxDropGemTier
+	}
+	def := GemTypes[rand.Intn(len(GemTypes))]
+	return NewGem(def.ID, rand.Intn(maxTier)+1)
+}
+
+// findGearItem locates an item in the inventory or equipment. Returns the
+// equipment slot when the item is equipped, otherwise the inventory index.
+func (e *Entity) findGearItem(itemID string) (*Item, string, int) {
+	if i := e.findInventoryItem(itemID); i >= 0 {
+		return &e.Inventory[i], "", i
+	}
+	for slot, item := range e.Equipment {
+		if item.ID == itemID {
+			return &item, slot, -1
+		}
+	}
+	return nil, "", -1
+}
+
+// storeGearItem writes back an item found with findGearItem.
+func (e *Entity) storeGearItem(item *Item, slot string, index int) {
+	if slot != "" {
+		e.Equipment[slot] = *item
+		e.RecalculateStats()
+		return
+	}
+	e.Inventory[index] = *item
+}
+
+// PerformSocket puts a gem from the inventory into a free socket of an
+// inventory or equipped item.
+func (w *World) PerformSocket(playerID, itemID, gemID string) (*Entity, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	player, ok := w.Entities[playerID]
+	if !ok || player.State == "DEAD" {
+		return nil, false
+	}
+
+	gemIdx := player.findInventoryItem(gemID)
+	if gemIdx < 0 || player.Inventory[gemIdx].Type != ItemGem {
+		return nil, false
+	}
+	if item, _, _ := player.findGearItem(itemID); item == nil || item.Type == ItemGem || len(item.Gems) >= item.Sockets {
+		return nil, false
+	}
+
+	gem, _ := player.removeInventoryItem(gemID)
+	// Removing the gem may have moved the target within the inventory
+	item, slot, index := player.findGearItem(itemID)
+	item.Gems = append(append([]Item{}, item.Gems...), gem)
+	item.RebuildStats()
+	player.storeGearItem(item, slot, index)
+	return player, true
+}
+
+// PerformUnsocket returns the gem in the given socket to the inventory.
+func (w *World) PerformUnsocket(playerID, itemID string, socket int) (*Entity, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	player, ok := w.Entities[playerID]
+	if !ok || player.State == "DEAD" {
+		return nil, false
+	}
+	if len(player.Inventory) >= MaxInventorySize {
+		return nil, false
+	}
+
+	item, slot, index := player.findGearItem(itemID)
+	if item == nil || socket < 0 || socket >= len(item.Gems) {
+		return nil, false
+	}
+
+	gem := item.Gems[socket]
+	gems := make([]Item, 0, len(item.Gems)-1)
+	gems = append(gems, item.Gems[:socket]...)
+	item.Gems = append(gems, item.Gems[socket+1:]...)
+	item.RebuildStats()
+	player.storeGearItem(item, slot, index)
+	player.Inventory = append(player.Inventory, gem)
+	return player, true
+}
+
+// PerformCombineGems trades GemsToCombine gems of the same type and tier plus
+// gold at the merchant for one gem of the next tier.
+func (w *World) PerformCombineGems(playerID string, gemIDs []string) (*Entity, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	player, ok := w.Entities[playerID]
+	if !ok || player.State == "DEAD" || !w.nearMerchant(player) {
+		return nil, false
+	}
+	if len(gemIDs) != GemsToCombine {
+		return nil, false
+	}
+
+	var gemType string
+	tier := 0
+	seen := make(map[string]bool)
+	for _, id := range gemIDs {
+		i := player.findInventoryItem(id)
+		if i < 0 || seen[id] {
+			return nil, false
+		}
+		seen[id] = true
+		gem := player.Inventory[i]
+		if gem.Type != ItemGem {
+			return nil, false
+		}
+		if tier == 0 {
+			gemType, tier = gem.GemType, gem.GemTier
+		} else if gem.GemType != gemType || gem.GemTier != tier {
+			return nil, false
+		}
+	}
+	if tier < 1 || tier >= MaxGemTier {
+		return nil, false
+	}
+
+	cost := GemCombineCost[tier-1]
+	if player.Gold < cost {
+		return nil, false
+	}
+	result := NewGem(gemType, tier+1)
+	if result == nil {
+		return nil, false
+	}
+
+	player.Gold -= cost
+	for _, id := range gemIDs {
+		player.removeInventoryItem(id)
+	}
+	player.Inventory = append(player.Inventory, *result)
+	return player, true
+}
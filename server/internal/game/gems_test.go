@@ -0,0 +1,94 @@
+package game
+
+import (
+	"testing"
+)
+
+func TestSocketAndUnsocket(t *testing.T) {
+	w := NewWorld()
+
+	helm := createItem(BaseItem{"Iron Helm", ItemArmor, "head", "defense", 4, ""}, RarityRare, 3.0, 0, 5)
+	helm.Sockets = 2
+	baseDefense := helm.Stats[StatDefense]
+	gem := NewGem("ruby", 2)
+
+	player := &Entity{
+		ID:        "p1",
+		Type:      TypePlayer,
+		Level:     5,
+		BaseStats: Stats{Strength: 10, Vitality: 10},
+		Equipment: map[string]Item{"head": *helm},
+		Inventory: []Item{*gem},
+	}
+	player.RecalculateStats()
+	w.Entities[player.ID] = player
+	strength := player.Stats.Strength
+
+	if _, ok := w.PerformSocket(player.ID, helm.ID, gem.ID); !ok {
+		t.Fatal("Socketing failed")
+	}
+	socketed := player.Equipment["head"]
+	if len(socketed.Gems) != 1 || len(player.Inventory) != 0 {
+		t.Fatalf("Gem not moved into socket: gems=%d inventory=%d", len(socketed.Gems), len(player.Inventory))
+	}
+	if socketed.Stats["strength"] != GemTierValues[1] || socketed.Stats[StatDefense] != baseDefense {
+		t.Errorf("Socketed stats = %v", socketed.Stats)
+	}
+	if player.Stats.Strength != strength+GemTierValues[1] {
+		t.Errorf("Strength = %d, want %d", player.Stats.Strength, strength+GemTierValues[1])
+	}
+
+	if _, ok := w.PerformUnsocket(player.ID, helm.ID, 0); !ok {
+		t.Fatal("Unsocketing failed")
+	}
+	if len(player.Equipment["head"].Gems) != 0 || len(player.Inventory) != 1 || player.Inventory[0].ID != gem.ID {
+		t.Fatal("Gem not returned to inventory")
+	}
+	if player.Stats.Strength != strength {
+		t.Errorf("Strength = %d after unsocket, want %d", player.Stats.Strength, strength)
+	}
+}
+
+func TestSocketLimit(t *testing.T) {
+	w := NewWorld()
+
+	boots := createItem(BaseItem{"Sandals", ItemArmor, "feet", "defense", 1, ""}, RarityCommon, 1.0, 0, 1)
+	boots.Sockets = 0
+	gem := NewGem("topaz", 1)
+	player := &Entity{ID: "p1", Type: TypePlayer, Inventory: []Item{*boots, *gem}}
+	w.Entities[player.ID] = player
+
+	if _, ok := w.PerformSocket(player.ID, boots.ID, gem.ID); ok {
+		t.Fatal("Socketed a gem into an item without sockets")
+	}
+	if _, ok := w.PerformEquip(player.ID, gem.ID, "head"); ok {
+		t.Fatal("Equipped a gem")
+	}
+}
+
+func TestCombineGems(t *testing.T) {
+	w := NewWorld()
+	merchant := w.Entities[MerchantID]
+
+	player := &Entity{ID: "p1", Type: TypePlayer, X: merchant.X, Z: merchant.Z, Gold: GemCombineCost[0]}
+	var ids []string
+	for i := 0; i < GemsToCombine; i++ {
+		gem := NewGem("sapphire", 1)
+		player.Inventory = append(player.Inventory, *gem)
+		ids = append(ids, gem.ID)
+	}
+	w.Entities[player.ID] = player
+
+	if _, ok := w.PerformCombineGems(player.ID, ids[:2]); ok {
+		t.Fatal("Combined too few gems")
+	}
+	if _, ok := w.PerformCombineGems(player.ID, ids); !ok {
+		t.Fatal("Combining failed")
+	}
+	if len(player.Inventory) != 1 || player.Inventory[0].GemTier != 2 || player.Inventory[0].GemType != "sapphire" {
+		t.Fatalf("Unexpected inventory after combine: %+v", player.Inventory)
+	}
+	if player.Gold != 0 {
+		t.Errorf("Gold = %d, want 0", player.Gold)
+	}
+}
@@ -32,6 +32,53 @@ type Item struct {
 	Value       int            `json:"value" bson:"value"`
 	Icon        string         `json:"icon,omitempty" bson:"icon"`
 	Description string         `json:"description,omitempty" bson:"description"`
+
+	// Generation details (empty for items created before affixes existed)
+	Base     string         `json:"base,omitempty" bson:"base"`         // BaseItem name
+	Implicit map[string]int `json:"implicit,omitempty" bson:"implicit"` // Base stat before affixes
+	Affixes  []ItemAffix    `json:"affixes,omitempty" bson:"affixes"`
+	UniqueID string         `json:"uniqueId,omitempty" bson:"unique_id"` // Named legendary
+	SetID    string         `json:"setId,omitempty" bson:"set_id"`
+
+	// Sockets
+	Sockets int    `json:"sockets,omitempty" bson:"sockets"`
+	Gems    []Item `json:"gems,omitempty" bson:"gems"`        // Socketed gems, in socket order
+	GemType string `json:"gemType,omitempty" bson:"gem_type"` // If Type == ItemGem
+	GemTier int    `json:"gemTier,omitempty" bson:"gem_tier"`
+
+	// Durability (MaxDurability 0 means the item never wears)
+	Durability    int `json:"durability" bson:"durability"`
+	MaxDurability int `json:"maxDurability,omitempty" bson:"max_durability"`
+}
+
+// clone deep copies an item so later in-place changes (gems, enchants, wear)
+// do not show through.
+func (i Item) clone() Item {
+	c := i
+	c.Stats = cloneStatMap(i.Stats)
+	c.Implicit = cloneStatMap(i.Implicit)
+	if i.Affixes != nil {
+		c.Affixes = make([]ItemAffix, len(i.Affixes))
+		copy(c.Affixes, i.Affixes)
+	}
+	if i.Gems != nil {
+		c.Gems = make([]Item, len(i.Gems))
+		for n, gem := range i.Gems {
+			c.Gems[n] = gem.clone()
+		}
+	}
+	return c
+}
+
+func cloneStatMap(m map[string]int) map[string]int {
+	if m == nil {
+		return nil
+	}
+	c := make(map[string]int, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
 }
 
 // Base Item Definitions (Matching Client)
@@ -78,17 +125,6 @@ var BaseItems = []BaseItem{
 
 var StatPool = []string{"strength", "dexterity", "intelligence", "wisdom", "vitality"}
 
-var StatNames = map[string]struct {
-	Prefix string
-	Suffix string
-}{
-	"strength":     {"Strong", "of the Bear"},
-	"dexterity":    {"Agile", "of the Tiger"},
-	"intelligence": {"Brilliant", "of the Owl"},
-	"wisdom":       {"Wise", "of the Eagle"},
-	"vitality":     {"Hearty", "of the Whale"},
-}
-
 func GenerateLoot(maxLevel int) *Item {
 	// 1. Roll for Rarity (Legendary 1%, Rare 29%, Uncommon 30%, Common 40%)
 	roll := rand.Float64()
@@ -113,31 +149,32 @@ func GenerateLoot(maxLevel int) *Item {
 	// 2. Determine Item Level (Random 1 to maxLevel)
 	level := rand.Intn(maxLevel) + 1
 
+	// Legendaries are named uniques; some rares are set pieces
+	if special := rollSpecialItem(rarity, level, ""); special != nil {
+		return special
+	}
+
 	// 3. Pick Base Item
 	baseItem := BaseItems[rand.Intn(len(BaseItems))]
 
 	return createItem(baseItem, rarity, multiplier, statCount, level)
 }
 
-func GenerateEliteLoot(level int) *Item {
-	// Rarity: 50% Uncommon, 40% Rare, 10% Legendary
-	roll := rand.Float64()
-	rarity := RarityUncommon
-	multiplier := 1.5
-	statCount := 1
-
-	if roll < 0.10 {
-		rarity = RarityLegendary
-		multiplier = 5.0
-		statCount = 5
-	} else if roll < 0.50 {
-		rarity = RarityRare
-		multiplier = 3.0
-		statCount = 2
+// Chance for a Rare drop to be a set piece instead
+const setPieceChance = 0.1
+
+// rollSpecialItem turns a Legendary roll into a unique and some Rare rolls into
+// set pieces. Returns nil when the regular affix item should be generated.
+func rollSpecialItem(rarity ItemRarity, level int, slot string) *Item {
+	switch rarity {
+	case RarityLegendary:
+		return GenerateUnique(level, slot)
+	case RarityRare:
+		if rand.Float64() < setPieceChance {
+			return GenerateSetPiece(level, slot)
+		}
 	}
-
-	baseItem := BaseItems[rand.Intn(len(BaseItems))]
-	return createItem(baseItem, rarity, multiplier, statCount, level)
+	return nil
 }
 
 func GenerateLootForSlot(slot string, level int) *Item {
@@ -176,6 +213,10 @@ func GenerateLootForSlot(slot string, level int) *Item {
 		statCount = 1
 	}
 
+	if special := rollSpecialItem(rarity, level, slot); special != nil {
+		return special
+	}
+
 	return createItem(baseItem, rarity, multiplier, statCount, level)
 }
 
@@ -184,84 +225,30 @@ func createItem(baseItem BaseItem, rarity ItemRarity, multiplier float64, statCo
 	// Base Stat scales with level and rarity multiplier
 	baseVal := int(float64(baseItem.BaseValue) * (1.0 + float64(level)*0.15) * multiplier)
 
-	itemStats := make(map[string]int)
-	itemStats[baseItem.BaseStat] = baseVal
-
-	// 5. Calculate Bonus Stats
-	name := baseItem.Name
+	item := &Item{
+		ID:       fmt.Sprintf("item-%d", rand.Int63()),
+		Name:     baseItem.Name,
+		Type:     baseItem.Type,
+		Rarity:   rarity,
+		Slot:     baseItem.Slot,
+		Level:    level,
+		Value:    level * 10 * int(multiplier),
+		Base:     baseItem.Name,
+		Implicit: map[string]int{baseItem.BaseStat: baseVal},
+		Sockets:  rollSockets(rarity),
+	}
+	item.MaxDurability = maxDurability(level)
+	item.Durability = item.MaxDurability
 
+	// 5. Roll Affixes (count comes from rarity)
 	if statCount > 0 {
-		// Calculate Total Stat Budget
-		rollPerLevel := 2.0 + rand.Float64()*2.0
-		totalBudget := int(rollPerLevel * float64(level) * multiplier)
-
-		// Select Stats
-		var selectedStats []string
-		if rarity == RarityLegendary {
-			// Shuffle StatPool to ensure random primary stat
-			shuffled := make([]string, len(StatPool))
-			copy(shuffled, StatPool)
-			rand.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
-			selectedStats = shuffled
-		} else {
-			// Pick random unique stats
-			pool := make([]string, len(StatPool))
-			copy(pool, StatPool)
-			for i := 0; i < statCount; i++ {
-				idx := rand.Intn(len(pool))
-				selectedStats = append(selectedStats, pool[idx])
-				// Remove from pool
-				pool[idx] = pool[len(pool)-1]
-				pool = pool[:len(pool)-1]
-			}
-		}
-
-		// Distribute Budget
-		if len(selectedStats) > 0 {
-			primaryStat := selectedStats[0]
-			primaryBudget := int(float64(totalBudget) * 0.5)
-
-			itemStats[primaryStat] += primaryBudget
-
-			if len(selectedStats) > 1 {
-				remainingBudget := totalBudget - primaryBudget
-				perStatBudget := remainingBudget / (len(selectedStats) - 1)
-				if perStatBudget < 1 {
-					perStatBudget = 1
-				}
-
-				for i := 1; i < len(selectedStats); i++ {
-					stat := selectedStats[i]
-					itemStats[stat] += perStatBudget
-				}
-			} else {
-				itemStats[primaryStat] += (totalBudget - primaryBudget)
-			}
-
-			// 6. Generate Name
-			if naming, ok := StatNames[primaryStat]; ok {
-				name = fmt.Sprintf("%s %s", naming.Prefix, name)
-			}
-
-			if len(selectedStats) > 1 {
-				secondaryStat := selectedStats[1]
-				if naming, ok := StatNames[secondaryStat]; ok {
-					name = fmt.Sprintf("%s %s", name, naming.Suffix)
-				}
-			} else if rarity == RarityLegendary {
-				name = fmt.Sprintf("%s of Legends", name)
-			}
-		}
+		item.Affixes = rollAffixes(baseItem.Slot, level, rarity, statCount)
 	}
 
-	return &Item{
-		ID:     fmt.Sprintf("item-%d", rand.Int63()),
-		Name:   name,
-		Type:   baseItem.Type,
-		Rarity: rarity,
-		Slot:   baseItem.Slot,
-		Level:  level,
-		Stats:  itemStats,
-		Value:  level * 10 * int(multiplier),
-	}
+	// 6. Derive Stats, Name and Description from the rolls
+	item.RebuildStats()
+	item.RebuildName()
+	item.RebuildDescription()
+
+	return item
 }
@@ -0,0 +1,223 @@
+package game
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// RarityOdds are per-drop chances; whatever is left over is Common.
+type RarityOdds struct {
+	Legendary float64
+	Rare      float64
+	Uncommon  float64
+}
+
+type LootEntry struct {
+	Base   string // BaseItem name
+	Weight int
+}
+
+type LootTable struct {
+	DropChance   float64 // Chance for a normal kill to drop one item
+	Guaranteed   int     // Items always dropped (elites/bosses)
+	GemChance    float64 // Chance for an extra gem drop
+	ExactLevel   bool    // Items drop at the enemy's level instead of 1..level
+	Rarity       RarityOdds
+	GoldPerLevel [2]int      // Min/Max gold per enemy level
+	Entries      []LootEntry // Weighted base items; empty means every BaseItem equally
+}
+
+// Zone is one of the spawn rings around town
+type Zone struct {
+	Name        string
+	MinR, MaxR  float64
+	Level       int
+	RarityBonus float64     // Multiplies Rare/Legendary odds
+	GoldBonus   float64     // Multiplies gold drops
+	Entries     []LootEntry // Extra weighted base items for this zone
+}
+
+var Zones = []Zone{
+	{Name: "Iron Weald", MinR: 60, MaxR: 150, Level: 5, RarityBonus: 1.0, GoldBonus: 1.0,
+		Entries: []LootEntry{{"Leather Tunic", 10}, {"Leather Pants", 10}, {"Iron Sword", 10}}},
+	{Name: "Shifting Sands", MinR: 160, MaxR: 250, Level: 10, RarityBonus: 1.1, GoldBonus: 1.1,
+		Entries: []LootEntry{{"Wooden Staff", 15}, {"Silk Hood", 10}, {"Sandals", 10}}},
+	{Name: "Abyssal Well", MinR: 260, MaxR: 350, Level: 15, RarityBonus: 1.2, GoldBonus: 1.25,
+		Entries: []LootEntry{{"Cleric Mace", 15}, {"Robes", 10}, {"Spell Tome", 10}}},
+	{Name: "Crystalline Spire", MinR: 360, MaxR: 450, Level: 20, RarityBonus: 1.35, GoldBonus: 1.5,
+		Entries: []LootEntry{{"Plate Mail", 15}, {"Iron Helm", 10}, {"Plate Greaves", 10}}},
+}
+
+// ZoneAt returns the spawn ring containing a position, or nil (town/wilds).
+func ZoneAt(x, z float64) *Zone {
+	r := math.Sqrt(x*x + z*z)
+	for i := range Zones {
+		if r >= Zones[i].MinR && r <= Zones[i].MaxR {
+			return &Zones[i]
+		}
+	}
+	return nil
+}
+
+// Per-subtype loot tables for normal enemies
+var EnemyLootTables = map[string]LootTable{
+	"Skeleton": {
+		DropChance: 0.45, GemChance: 0.05, Rarity: RarityOdds{Legendary: 0.005, Rare: 0.20, Uncommon: 0.30}, GoldPerLevel: [2]int{1, 8},
+		Entries: []LootEntry{{"Iron Sword", 20}, {"Leather Cap", 15}, {"Leather Boots", 15}, {"Wooden Shield", 10}},
+	},
+	"Imp": {
+		DropChance: 0.5, GemChance: 0.06, Rarity: RarityOdds{Legendary: 0.01, Rare: 0.25, Uncommon: 0.30}, GoldPerLevel: [2]int{2, 10},
+		Entries: []LootEntry{{"Steel Dagger", 20}, {"Wooden Staff", 15}, {"Silk Skirt", 10}, {"Spell Tome", 10}},
+	},
+	"DemonOrc": {
+		DropChance: 0.55, GemChance: 0.07, Rarity: RarityOdds{Legendary: 0.012, Rare: 0.28, Uncommon: 0.30}, GoldPerLevel: [2]int{3, 12},
+		Entries: []LootEntry{{"Iron Sword", 15}, {"Plate Mail", 15}, {"Iron Boots", 10}, {"Cleric Mace", 10}},
+	},
+	"Construct": {
+		DropChance: 0.6, GemChance: 0.08, Rarity: RarityOdds{Legendary: 0.015, Rare: 0.30, Uncommon: 0.30}, GoldPerLevel: [2]int{4, 15},
+		Entries: []LootEntry{{"Plate Greaves", 15}, {"Iron Helm", 15}, {"Plate Mail", 10}, {"Wooden Shield", 10}},
+	},
+}
+
+// Default table for subtypes without their own entry
+var DefaultLootTable = LootTable{
+	DropChance: 0.5, GemChance: 0.05, Rarity: RarityOdds{Legendary: 0.01, Rare: 0.29, Uncommon: 0.30}, GoldPerLevel: [2]int{1, 10},
+}
+
+// Elites always drop several items at their own level with better odds
+var EliteLootTable = LootTable{
+	Guaranteed: 3, GemChance: 0.5, ExactLevel: true, Rarity: RarityOdds{Legendary: 0.10, Rare: 0.40, Uncommon: 0.50}, GoldPerLevel: [2]int{10, 30},
+}
+
+// Bad-luck protection: after PityThreshold drops without a Legendary, every
+// further drop adds PityStep to the Legendary chance.
+const (
+	PityThreshold = 150
+	PityStep      = 0.002
+	MaxMagicFind  = 3.0 // +300%
+
+	DefaultLootOwnershipWindow = 60 * time.Second
+)
+
+// Affix count per rarity for table-driven drops
+var rarityAffixCount = map[ItemRarity]int{
+	RarityCommon:    0,
+	RarityUncommon:  1,
+	RarityRare:      2,
+	RarityLegendary: 5,
+}
+
+// LootTableFor picks the table for an enemy (elites use the elite table).
+func LootTableFor(e *Entity) LootTable {
+	if e.IsElite() {
+		return EliteLootTable
+	}
+	if t, ok := EnemyLootTables[e.SubType]; ok {
+		return t
+	}
+	return DefaultLootTable
+}
+
+// RollRarity applies zone bonus, magic find and the pity counter to the table odds.
+func RollRarity(odds RarityOdds, zone *Zone, magicFind float64, pity int) ItemRarity {
+	bonus := 1.0 + math.Min(magicFind, MaxMagicFind)
+	if zone != nil && zone.RarityBonus > 0 {
+		bonus *= zone.RarityBonus
+	}
+
+	legendary := odds.Legendary * bonus
+	if pity > PityThreshold {
+		legendary += float64(pity-PityThreshold) * PityStep
+	}
+	rare := odds.Rare * bonus
+
+	roll := rand.Float64()
+	switch {
+	case roll < legendary:
+		return RarityLegendary
+	case roll < legendary+rare:
+		return RarityRare
+	case roll < legendary+rare+odds.Uncommon:
+		return RarityUncommon
+	}
+	return RarityCommon
+}
+
+func pickLootBase(entries []LootEntry) BaseItem {
+	total := 0
+	for _, e := range entries {
+		total += e.Weight
+	}
+	if total > 0 {
+		roll := rand.Intn(total)
+		for _, e := range entries {
+			roll -= e.Weight
+			if roll < 0 {
+				if base, ok := findBaseItem(e.Base); ok {
+					return base
+				}
+				break
+			}
+		}
+	}
+	return BaseItems[rand.Intn(len(BaseItems))]
+}
+
+// GenerateLootFromTable rolls one item from a table for an enemy of the given level.
+func GenerateLootFromTable(table LootTable, zone *Zone, level int, magicFind float64, pity int) *Item {
+	if level < 1 {
+		level = 1
+	}
+	itemLevel := level
+	if !table.ExactLevel {
+		itemLevel = rand.Intn(level) + 1
+	}
+
+	rarity := RollRarity(table.Rarity, zone, magicFind, pity)
+	if special := rollSpecialItem(rarity, itemLevel, ""); special != nil {
+		return special
+	}
+
+	entries := table.Entries
+	if zone != nil {
+		entries = append(append([]LootEntry{}, entries...), zone.Entries...)
+	}
+	base := pickLootBase(entries)
+	return createItem(base, rarity, RarityMultiplier[rarity], rarityAffixCount[rarity], itemLevel)
+}
+
+// RollGold rolls the gold reward for a kill.
+func RollGold(table LootTable, zone *Zone, level int) int {
+	if level <= 0 {
+		return 0
+	}
+	min, max := table.GoldPerLevel[0], table.GoldPerLevel[1]
+	perLevel := min
+	if max > min {
+		perLevel += rand.Intn(max - min + 1)
+	}
+	gold := 10 + perLevel*level
+	if zone != nil && zone.GoldBonus > 0 {
+		gold = int(float64(gold) * zone.GoldBonus)
+	}
+	return gold
+}
+
+// assignLootOwner reserves a drop for the killer until the ownership window
+// expires. Caller must hold the lock.
+func (w *World) assignLootOwner(loot, killer *Entity) {
+	if killer == nil || killer.Type != TypePlayer || w.LootOwnershipWindow <= 0 {
+		return
+	}
+	loot.LootOwner = killer.ID
+	loot.LootFreeAt = loot.LootTime.Add(w.LootOwnershipWindow).UnixMilli()
+}
+
+// lootableBy reports whether a player may pick up this drop right now.
+func (e *Entity) lootableBy(playerID string, now time.Time) bool {
+	if e.LootOwner == "" || e.LootOwner == playerID {
+		return true
+	}
+	return now.UnixMilli() >= e.LootFreeAt
+}
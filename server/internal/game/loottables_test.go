@@ -0,0 +1,106 @@
+package game
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLootTableBaseItems(t *testing.T) {
+	check := func(owner string, entries []LootEntry) {
+		for _, e := range entries {
+			if _, ok := findBaseItem(e.Base); !ok {
+				t.Errorf("%s lists unknown base item %q", owner, e.Base)
+			}
+		}
+	}
+	for subType, table := range EnemyLootTables {
+		check(subType, table.Entries)
+	}
+	for _, zone := range Zones {
+		check(zone.Name, zone.Entries)
+	}
+}
+
+func TestPityGuaranteesLegendary(t *testing.T) {
+	odds := RarityOdds{Legendary: 0, Rare: 0, Uncommon: 0}
+	pity := PityThreshold + int(1/PityStep) + 1
+
+	for i := 0; i < 100; i++ {
+		if r := RollRarity(odds, nil, 0, pity); r != RarityLegendary {
+			t.Fatalf("Rolled %s with maxed pity, want Legendary", r)
+		}
+		if r := RollRarity(odds, nil, 0, 0); r != RarityCommon {
+			t.Fatalf("Rolled %s with zero odds, want Common", r)
+		}
+	}
+}
+
+func TestEliteKillDropsAndPity(t *testing.T) {
+	w := NewWorld()
+	for id := range w.Entities {
+		delete(w.Entities, id)
+	}
+
+	player := &Entity{ID: "p1", Type: TypePlayer, Level: 1, MaxExperience: 100, BaseStats: Stats{Vitality: 10}}
+	player.RecalculateStats()
+	elite := &Entity{ID: "elite-Skeleton-1", Type: TypeEnemy, SubType: "Skeleton", Level: 5, X: 100}
+	w.Entities[player.ID] = player
+	w.Entities[elite.ID] = elite
+
+	w.handleDeath(elite, player)
+
+	drops := 0
+	legendary := false
+	for _, e := range w.Entities {
+		if e.Type == TypeLoot && e.LootItem.Type != ItemGem {
+			drops++
+			if e.LootItem.Rarity == RarityLegendary {
+				legendary = true
+			}
+		}
+	}
+	if drops != EliteLootTable.Guaranteed {
+		t.Errorf("Elite dropped %d items, want %d", drops, EliteLootTable.Guaranteed)
+	}
+	if player.Gold <= 0 {
+		t.Error("Elite kill awarded no gold")
+	}
+	if !legendary && player.LootPity != drops {
+		t.Errorf("LootPity = %d, want %d", player.LootPity, drops)
+	}
+}
+
+func TestLootOwnership(t *testing.T) {
+	w := NewWorld()
+	w.LootOwnershipWindow = time.Minute
+
+	killer := &Entity{ID: "p1", Type: TypePlayer}
+	other := &Entity{ID: "p2", Type: TypePlayer}
+	w.Entities[killer.ID] = killer
+	w.Entities[other.ID] = other
+
+	loot := &Entity{ID: "loot-1", Type: TypeLoot, LootItem: &Item{ID: "i1"}, LootTime: time.Now()}
+	w.assignLootOwner(loot, killer)
+	w.Entities[loot.ID] = loot
+
+	if loot.LootOwner != killer.ID {
+		t.Fatalf("LootOwner = %q, want %q", loot.LootOwner, killer.ID)
+	}
+	if _, ok := w.PerformPickup(other.ID, loot.ID); ok {
+		t.Fatal("Other player picked up reserved loot")
+	}
+
+	// Window expired: free-for-all
+	loot.LootFreeAt = time.Now().Add(-time.Second).UnixMilli()
+	if _, ok := w.PerformPickup(other.ID, loot.ID); !ok {
+		t.Fatal("Other player could not pick up loot after the window")
+	}
+
+	// Owner can always pick up
+	mine := &Entity{ID: "loot-2", Type: TypeLoot, LootItem: &Item{ID: "i2"}, LootTime: time.Now()}
+	w.assignLootOwner(mine, killer)
+	w.Entities[mine.ID] = mine
+	if _, ok := w.PerformPickup(killer.ID, mine.ID); !ok {
+		t.Fatal("Owner could not pick up own loot")
+	}
+}
@@ -0,0 +1,169 @@
+package game
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	MerchantID    = "merchant-1"
+	MerchantRange = 8.0 // Max distance to trade with the merchant
+
+	MerchantStockSize       = 12
+	MerchantStockMaxLevel   = 20 // Matches the highest spawn area
+	MerchantRestockInterval = 10 * time.Minute
+
+	MaxBuybackItems = 10
+	GambleCost      = 500
+)
+
+// Price multiplier per rarity for merchant stock
+var RarityPriceMultiplier = map[ItemRarity]int{
+	RarityCommon:    1,
+	RarityUncommon:  2,
+	RarityRare:      5,
+	RaritySet:       10,
+	RarityLegendary: 20,
+}
+
+type MerchantItem struct {
+	Item  Item `json:"item"`
+	Price int  `json:"price"`
+}
+
+type MerchantView struct {
+	Stock     []MerchantItem `json:"stock"`
+	Buyback   []MerchantItem `json:"buyback"`
+	RestockAt int64          `json:"restockAt"` // Unix seconds
+}
+
+// StockPrice is what the merchant charges for an item from his stock.
+func StockPrice(item Item) int {
+	mult, ok := RarityPriceMultiplier[item.Rarity]
+	if !ok {
+		mult = 1
+	}
+	return (50 + item.Level*25) * mult
+}
+
+// SellPrice is what the merchant pays for an item (and charges to buy it back).
+func SellPrice(item Item) int {
+	if item.Value <= 0 {
+		return 1
+	}
+	return item.Value
+}
+
+// restockMerchant rolls a fresh set of items. Caller must hold the lock.
+func (w *World) restockMerchant() {
+	stock := make([]Item, 0, MerchantStockSize)
+	for len(stock) < MerchantStockSize {
+		if item := GenerateLoot(MerchantStockMaxLevel); item != nil {
+			stock = append(stock, *item)
+		}
+	}
+	w.MerchantStock = stock
+	w.MerchantRestockTime = time.Now().Add(MerchantRestockInterval)
+}
+
+// nearMerchant reports whether the player is close enough to the merchant NPC.
+// Caller must hold the lock.
+func (w *World) nearMerchant(player *Entity) bool {
+	merchant, ok := w.Entities[MerchantID]
+	if !ok {
+		return false
+	}
+	dx := player.X - merchant.X
+	dz := player.Z - merchant.Z
+	return math.Sqrt(dx*dx+dz*dz) <= MerchantRange
+}
+
+func (w *World) GetMerchantView(playerID string) (*MerchantView, bool) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	player, ok := w.Entities[playerID]
+	if !ok || !w.nearMerchant(player) {
+		return nil, false
+	}
+
+	view := &MerchantView{
+		Stock:     make([]MerchantItem, 0, len(w.MerchantStock)),
+		Buyback:   make([]MerchantItem, 0, len(w.Buyback[playerID])),
+		RestockAt: w.MerchantRestockTime.Unix(),
+	}
+	for _, item := range w.MerchantStock {
+		view.Stock = append(view.Stock, MerchantItem{Item: item, Price: StockPrice(item)})
+	}
+	for _, item := range w.Buyback[playerID] {
+		view.Buyback = append(view.Buyback, MerchantItem{Item: item, Price: SellPrice(item)})
+	}
+	return view, true
+}
+
+func (w *World) PerformBuyStock(playerID, itemID string) (*Entity, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	player, ok := w.Entities[playerID]
+	if !ok || player.State == "DEAD" || !w.nearMerchant(player) {
+		return nil, false
+	}
+	if len(player.Inventory) >= MaxInventorySize {
+		return nil, false
+	}
+
+	for i, item := range w.MerchantStock {
+		if item.ID != itemID {
+			continue
+		}
+		price := StockPrice(item)
+		if player.Gold < price {
+			return nil, false
+		}
+		player.Gold -= price
+		player.Inventory = append(player.Inventory, item)
+		// Stock is shared; once sold it is gone until the next restock
+		w.MerchantStock = append(w.MerchantStock[:i], w.MerchantStock[i+1:]...)
+		return player, true
+	}
+	return nil, false
+}
+
+func (w *World) PerformBuyback(playerID, itemID string) (*Entity, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	player, ok := w.Entities[playerID]
+	if !ok || player.State == "DEAD" || !w.nearMerchant(player) {
+		return nil, false
+	}
+	if len(player.Inventory) >= MaxInventorySize {
+		return nil, false
+	}
+
+	buyback := w.Buyback[playerID]
+	for i, item := range buyback {
+		if item.ID != itemID {
+			continue
+		}
+		price := SellPrice(item)
+		if player.Gold < price {
+			return nil, false
+		}
+		player.Gold -= price
+		player.Inventory = append(player.Inventory, item)
+		w.Buyback[playerID] = append(buyback[:i], buyback[i+1:]...)
+		return player, true
+	}
+	return nil, false
+}
+
+// addBuyback remembers a sold item, newest first. Caller must hold the lock.
+func (w *World) addBuyback(playerID string, item Item) {
+	list := append([]Item{item}, w.Buyback[playerID]...)
+	if len(list) > MaxBuybackItems {
+		list = list[:MaxBuybackItems]
+	}
+	w.Buyback[playerID] = list
+}
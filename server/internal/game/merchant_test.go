@@ -0,0 +1,51 @@
+package game
+
+import (
+	"testing"
+)
+
+func TestSellAndBuyback(t *testing.T) {
+	w := NewWorld()
+	p := &Entity{ID: "player-1", Type: TypePlayer, State: "IDLE", X: 5, Z: 7,
+		Inventory: []Item{{ID: "item-1", Name: "Iron Sword", Value: 30}}}
+	w.AddEntity(p)
+
+	if _, ok := w.PerformSell(p.ID, "item-1"); !ok {
+		t.Fatal("PerformSell failed next to the merchant")
+	}
+	if p.Gold != 30 {
+		t.Errorf("Gold after sell = %d, want 30", p.Gold)
+	}
+
+	view, ok := w.GetMerchantView(p.ID)
+	if !ok || len(view.Buyback) != 1 {
+		t.Fatalf("Buyback list missing sold item: %+v", view)
+	}
+	if len(view.Stock) != MerchantStockSize {
+		t.Errorf("Stock size = %d, want %d", len(view.Stock), MerchantStockSize)
+	}
+
+	if _, ok := w.PerformBuyback(p.ID, "item-1"); !ok {
+		t.Fatal("PerformBuyback failed")
+	}
+	if p.Gold != 0 || len(p.Inventory) != 1 {
+		t.Errorf("After buyback gold=%d inv=%d, want 0/1", p.Gold, len(p.Inventory))
+	}
+}
+
+func TestMerchantProximity(t *testing.T) {
+	w := NewWorld()
+	p := &Entity{ID: "player-1", Type: TypePlayer, State: "IDLE", X: 40, Z: 40, Gold: 100000,
+		Inventory: []Item{{ID: "item-1", Value: 30}}}
+	w.AddEntity(p)
+
+	if _, ok := w.PerformSell(p.ID, "item-1"); ok {
+		t.Error("PerformSell succeeded away from the merchant")
+	}
+	if _, ok := w.PerformBuyStock(p.ID, w.MerchantStock[0].ID); ok {
+		t.Error("PerformBuyStock succeeded away from the merchant")
+	}
+	if _, ok := w.PerformBuyGamble(p.ID, "head"); ok {
+		t.Error("PerformBuyGamble succeeded away from the merchant")
+	}
+}
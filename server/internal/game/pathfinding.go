@@ -0,0 +1,212 @@
+package game
+
+import (
+	"container/heap"
+	"math"
+)
+
+const (
+	MaxPathNodes   = 20000 // Cells expanded before a search gives up
+	RepathDistance = 2.0   // Replan once the goal has moved this far
+)
+
+// Waypoint is a point on a planned path
+type Waypoint struct {
+	X float64
+	Z float64
+}
+
+type pathNode struct {
+	cell  int
+	f     float64
+	index int
+}
+
+type pathQueue []*pathNode
+
+func (q pathQueue) Len() int            { return len(q) }
+func (q pathQueue) Less(i, j int) bool  { return q[i].f < q[j].f }
+func (q pathQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i]; q[i].index = i; q[j].index = j }
+func (q *pathQueue) Push(x interface{}) { n := x.(*pathNode); n.index = len(*q); *q = append(*q, n) }
+func (q *pathQueue) Pop() interface{} {
+	old := *q
+	n := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return n
+}
+
+var pathDirs = [8][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}, {1, 1}, {1, -1}, {-1, 1}, {-1, -1}}
+
+// octile is the A* heuristic for 8-way movement on the grid.
+func octile(ax, az, bx, bz int) float64 {
+	dx := math.Abs(float64(ax - bx))
+	dz := math.Abs(float64(az - bz))
+	return math.Max(dx, dz) + (math.Sqrt2-1)*math.Min(dx, dz)
+}
+
+// FindPath runs A* over the grid and returns smoothed waypoints ending at the
+// goal. It fails if the goal is blocked or too far to search.
+func (m *CollisionMap) FindPath(fromX, fromZ, toX, toZ float64) ([]Waypoint, bool) {
+	sx, sz := m.cellOf(fromX, fromZ)
+	gx, gz := m.cellOf(toX, toZ)
+	if m.cellBlocked(gx, gz) {
+		return nil, false
+	}
+	start, goal := sz*m.width+sx, gz*m.width+gx
+
+	g := map[int]float64{start: 0}
+	came := map[int]int{}
+	closed := map[int]bool{}
+	open := &pathQueue{}
+	heap.Push(open, &pathNode{cell: start, f: octile(sx, sz, gx, gz)})
+
+	for open.Len() > 0 {
+		cur := heap.Pop(open).(*pathNode).cell
+		if cur == goal {
+			return m.smoothPath(fromX, fromZ, toX, toZ, m.walkBack(came, start, goal)), true
+		}
+		if closed[cur] {
+			continue
+		}
+		closed[cur] = true
+		if len(closed) > MaxPathNodes {
+			return nil, false
+		}
+
+		cx, cz := cur%m.width, cur/m.width
+		for _, d := range pathDirs {
+			nx, nz := cx+d[0], cz+d[1]
+			if m.cellBlocked(nx, nz) {
+				continue
+			}
+			cost := 1.0
+			if d[0] != 0 && d[1] != 0 {
+				// No cutting corners past a blocked cell
+				if m.cellBlocked(cx+d[0], cz) || m.cellBlocked(cx, cz+d[1]) {
+					continue
+				}
+				cost = math.Sqrt2
+			}
+			next := nz*m.width + nx
+			if closed[next] {
+				continue
+			}
+			score := g[cur] + cost
+			if old, seen := g[next]; seen && score >= old {
+				continue
+			}
+			g[next] = score
+			came[next] = cur
+			heap.Push(open, &pathNode{cell: next, f: score + octile(nx, nz, gx, gz)})
+		}
+	}
+	return nil, false
+}
+
+// walkBack turns the A* parent links into cell centres from start to goal.
+func (m *CollisionMap) walkBack(came map[int]int, start, goal int) []Waypoint {
+	var cells []int
+	for c := goal; c != start; c = came[c] {
+		cells = append(cells, c)
+	}
+	path := make([]Waypoint, len(cells))
+	for i, c := range cells {
+		x, z := m.cellCenter(c%m.width, c/m.width)
+		path[len(cells)-1-i] = Waypoint{X: x, Z: z}
+	}
+	return path
+}
+
+// smoothPath drops waypoints that can be skipped in a straight line.
+func (m *CollisionMap) smoothPath(fromX, fromZ, toX, toZ float64, path []Waypoint) []Waypoint {
+	if len(path) == 0 {
+		return []Waypoint{{X: toX, Z: toZ}}
+	}
+	path[len(path)-1] = Waypoint{X: toX, Z: toZ}
+
+	var out []Waypoint
+	cur := Waypoint{X: fromX, Z: fromZ}
+	for i := 0; i < len(path); {
+		j := len(path) - 1
+		for j > i && !m.pathClear(cur.X, cur.Z, path[j].X, path[j].Z) {
+			j--
+		}
+		out = append(out, path[j])
+		cur = path[j]
+		i = j + 1
+	}
+	return out
+}
+
+// stepToward moves an enemy up to maxDist straight towards a point without
+// entering the safe zone or terrain. Returns false if blocked.
+func (w *World) stepToward(e *Entity, x, z, maxDist float64) bool {
+	dx := x - e.X
+	dz := z - e.Z
+	dist := math.Sqrt(dx*dx + dz*dz)
+	if dist == 0 {
+		return true
+	}
+	if maxDist > dist {
+		maxDist = dist
+	}
+	newX := e.X + (dx/dist)*maxDist
+	newZ := e.Z + (dz/dist)*maxDist
+	if InSafeZone(newX, newZ) {
+		return false
+	}
+	// Anything already stuck in terrain may walk out of it
+	if m := w.Collision; m != nil && m.Collides(newX, newZ, AgentRadius) && !m.Collides(e.X, e.Z, AgentRadius) {
+		return false
+	}
+	e.X = newX
+	e.Z = newZ
+	e.Rotation = math.Atan2(dx, dz)
+	return true
+}
+
+// stepAway moves directly away from a point. Returns false if blocked.
+func (w *World) stepAway(e *Entity, x, z, maxDist float64) bool {
+	dx := e.X - x
+	dz := e.Z - z
+	if dx == 0 && dz == 0 {
+		dx = 1
+	}
+	dist := math.Sqrt(dx*dx + dz*dz)
+	return w.stepToward(e, e.X+dx/dist*maxDist, e.Z+dz/dist*maxDist, maxDist)
+}
+
+// navigate moves towards a point, walking straight when the way is clear and
+// following an A* path around terrain and the town otherwise. Returns false if
+// there is no way through; the search is not retried until the goal moves.
+// Caller must hold the lock.
+func (w *World) navigate(e *Entity, x, z, maxDist float64) bool {
+	m := w.Collision
+	if m == nil || m.pathClear(e.X, e.Z, x, z) {
+		e.Path, e.PathFailed = nil, false
+		return w.stepToward(e, x, z, maxDist)
+	}
+	goalMoved := math.Hypot(x-e.PathGoal.X, z-e.PathGoal.Z) > RepathDistance
+	if e.PathFailed && !goalMoved {
+		return false
+	}
+	if len(e.Path) == 0 || goalMoved {
+		e.PathGoal = Waypoint{X: x, Z: z}
+		path, ok := m.FindPath(e.X, e.Z, x, z)
+		if !ok {
+			e.Path, e.PathFailed = nil, true
+			return false
+		}
+		e.Path, e.PathFailed = path, false
+	}
+
+	next := e.Path[0]
+	if !w.stepToward(e, next.X, next.Z, maxDist) {
+		e.Path, e.PathFailed = nil, true
+		return false
+	}
+	if math.Hypot(next.X-e.X, next.Z-e.Z) < 0.05 {
+		e.Path = e.Path[1:]
+	}
+	return true
+}
@@ -0,0 +1,161 @@
+package game
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// wallMap is a 400-wide world with a wall east of town across x=100 from
+// z=-30 to z=30.
+func wallMap() *CollisionMap {
+	return NewCollisionMap(WorldFile{Bounds: 400, CellSize: 1, Colliders: []Collider{
+		{Kind: "wall", MinX: 99, MinZ: -30, MaxX: 101, MaxZ: 30},
+		{Kind: "rock", MinX: 140, MinZ: 40, MaxX: 144, MaxZ: 44},
+	}})
+}
+
+func TestDefaultWorldMatchesClientTown(t *testing.T) {
+	m := DefaultCollisionMap()
+	if m.Half != 500 {
+		t.Errorf("Bounds half = %.0f, want 500", m.Half)
+	}
+	if !m.Collides(0, -50, AgentRadius) || !m.Collides(-50, 0, AgentRadius) || !m.Collides(50, 20, AgentRadius) || !m.Collides(-20, 50, AgentRadius) {
+		t.Error("Town fence should block north, west, east and south walls")
+	}
+	if m.Collides(0, 50, AgentRadius) {
+		t.Error("South gate should be open")
+	}
+	if m.Collides(100, 100, AgentRadius) || !m.Collides(600, 0, AgentRadius) {
+		t.Error("Open ground is free; outside the world is not")
+	}
+
+	if _, err := LoadCollisionMap([]byte(`{"bounds": 0}`)); err == nil {
+		t.Error("World file without bounds should fail to load")
+	}
+}
+
+func TestFindPathAroundWall(t *testing.T) {
+	m := wallMap()
+
+	path, ok := m.FindPath(90, 0, 110, 0)
+	if !ok || len(path) < 2 {
+		t.Fatalf("Path = %v, %v", path, ok)
+	}
+	last := path[len(path)-1]
+	if last.X != 110 || last.Z != 0 {
+		t.Errorf("Path should end at the goal, got %+v", last)
+	}
+	prev := Waypoint{X: 90, Z: 0}
+	for _, wp := range path {
+		if !m.SegmentClear(prev.X, prev.Z, wp.X, wp.Z, AgentRadius) {
+			t.Fatalf("Leg %+v -> %+v crosses terrain", prev, wp)
+		}
+		prev = wp
+	}
+
+	if _, ok := m.FindPath(90, 0, 142, 42); ok {
+		t.Error("Goal inside a rock should have no path")
+	}
+}
+
+func TestEnemiesPathAroundTown(t *testing.T) {
+	w := NewWorld()
+	w.Entities = map[string]*Entity{}
+	w.Collision = NewCollisionMap(WorldFile{Bounds: 400, CellSize: 1})
+	e := &Entity{ID: "Skeleton-x", Type: TypeEnemy, X: -60, Z: 0}
+
+	// The straight line runs through town; the path goes around it
+	for i := 0; i < 2000 && math.Hypot(e.X-60, e.Z) > 0.1; i++ {
+		if !w.navigate(e, 60, 0, 0.5) {
+			t.Fatalf("Enemy stuck at %.1f,%.1f", e.X, e.Z)
+		}
+		if InSafeZone(e.X, e.Z) {
+			t.Fatalf("Enemy entered town at %.1f,%.1f", e.X, e.Z)
+		}
+	}
+	if math.Hypot(e.X-60, e.Z) > 0.1 {
+		t.Errorf("Enemy should reach the far side of town, at %.1f,%.1f", e.X, e.Z)
+	}
+
+	// A goal in town fails once and is not searched again until it moves
+	if w.navigate(e, 40, 0, 0.5) || !e.PathFailed {
+		t.Fatal("Goal in town should be unreachable")
+	}
+	e.PathGoal = Waypoint{X: 40, Z: 1} // Within RepathDistance: no new search
+	if w.navigate(e, 40, 0, 0.5) || e.PathGoal.Z != 1 {
+		t.Error("Failed search should not be retried for the same goal")
+	}
+	if !w.navigate(e, 80, 20, 0.5) || e.PathFailed {
+		t.Error("A reachable goal should clear the failure")
+	}
+}
+
+func TestEnemyWalksAroundWall(t *testing.T) {
+	w, clock, player := aiWorld(110, 0)
+	w.Collision = NewCollisionMap(WorldFile{Bounds: 400, CellSize: 1, Colliders: []Collider{
+		{Kind: "wall", MinX: 99, MinZ: -20, MaxX: 101, MaxZ: 20},
+	}})
+	orc := addEnemy(w, "DemonOrc-x", "DemonOrc", 95, 0)
+	orc.ChargeReady = clock.Now().Add(time.Hour) // Walk, don't charge
+	orc.addThreat(player.ID, 10)                 // Hated targets are tracked out of sight
+
+	for i := 0; i < 400 && distance(orc, player) > MeleeRange; i++ {
+		tick(w, clock, 1)
+		if w.Collision.Collides(orc.X, orc.Z, AgentRadius) {
+			t.Fatalf("Enemy walked into the wall at %.1f,%.1f", orc.X, orc.Z)
+		}
+	}
+	if distance(orc, player) > MeleeRange {
+		t.Errorf("Enemy should path around the wall, at %.1f,%.1f", orc.X, orc.Z)
+	}
+}
+
+func TestPlayerMovementAndChargeStopAtTerrain(t *testing.T) {
+	w := NewWorld()
+	w.Entities = map[string]*Entity{}
+	p := &Entity{ID: "p1", Type: TypePlayer, SubType: "Fighter", Health: 100, MaxHealth: 100, Mana: 100, MaxMana: 100, X: 20, Z: 45}
+	w.Entities[p.ID] = p
+
+	// Into the south fence: pushed back out
+	w.MovePlayer(p.ID, 20, 0, 49.2, 0, "MOVING", 0)
+	if w.Collision.Collides(p.X, p.Z, AgentRadius) || p.Z >= 49 {
+		t.Errorf("Player inside fence at %.2f,%.2f", p.X, p.Z)
+	}
+
+	// Through the gate is fine, and the respawn teleport still works
+	w.MovePlayer(p.ID, 0, 0, 55, 0, "MOVING", 0)
+	if p.X != 0 || p.Z != 55 {
+		t.Errorf("Gate move = %.1f,%.1f", p.X, p.Z)
+	}
+	w.MovePlayer(p.ID, 0, 0, 0, 0, "IDLE", 0)
+	if p.X != 0 || p.Z != 0 {
+		t.Errorf("Teleport to town = %.1f,%.1f", p.X, p.Z)
+	}
+
+	// Charge from town straight at the east fence
+	p.X, p.Z = 40, 0
+	p.IsCharging, p.ChargeTargetX, p.ChargeTargetZ = true, 70, 0
+	for i := 0; i < 40 && p.IsCharging; i++ {
+		w.Update(0.05)
+	}
+	if p.IsCharging || p.X > 49-AgentRadius || math.IsNaN(p.X) {
+		t.Errorf("Charge should stop at the fence, at %.2f charging %v", p.X, p.IsCharging)
+	}
+}
+
+func TestLineOfSight(t *testing.T) {
+	m := wallMap()
+	if m.LineOfSight(90, 0, 110, 0) {
+		t.Error("Wall should block sight across it")
+	}
+	if !m.LineOfSight(90, 40, 110, 40) || !m.LineOfSight(90, -20, 90, 20) {
+		t.Error("Clear lines should have sight")
+	}
+	if m.LineOfSight(90, 29, 110, 31) {
+		t.Error("Diagonal clipping the wall end should be blocked")
+	}
+	if m.LineOfSight(130, 30, 150, 50) {
+		t.Error("Rock should block sight")
+	}
+}
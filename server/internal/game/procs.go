@@ -0,0 +1,199 @@
+package game
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+type ProcTrigger string
+
+const (
+	ProcOnHit     ProcTrigger = "hit"
+	ProcOnKill    ProcTrigger = "kill"
+	ProcOnAbility ProcTrigger = "ability"
+)
+
+type ProcEffect string
+
+const (
+	EffectChainFireball ProcEffect = "chain_fireball" // Fires a new Fireball from the target at the nearest enemy (Value = % damage)
+	EffectBurningTrail  ProcEffect = "burning_trail"  // Charge leaves fire patches behind (Value = damage per tick)
+	EffectHeal          ProcEffect = "heal"           // Heals Value % of max health
+	EffectRestoreMana   ProcEffect = "restore_mana"   // Restores Value mana
+	EffectExplode       ProcEffect = "explode"        // Target bursts, hitting enemies nearby (Value = damage)
+)
+
+// ProcDef is a special effect granted by a unique item or set bonus
+type ProcDef struct {
+	Trigger ProcTrigger `json:"trigger"`
+	Effect  ProcEffect  `json:"effect"`
+	Chance  float64     `json:"chance"`            // 0-1
+	Value   int         `json:"value"`             // Effect magnitude
+	Source  string      `json:"source,omitempty"`  // Only fire for this ability/projectile (e.g. "Fireball", "Charge")
+	Summary string      `json:"summary,omitempty"` // Tooltip text
+}
+
+const (
+	fireTrailSpacing  = 2.0
+	fireTrailRadius   = 2.0
+	fireTrailDuration = 3 * time.Second
+	fireTrailTick     = 500 * time.Millisecond
+	explodeRadius     = 6.0
+	chainFireballSeek = 20.0
+)
+
+// triggerProcs fires every matching proc the actor has equipped.
+// target may be nil for ability procs. Caller must hold the lock.
+func (w *World) triggerProcs(actor, target *Entity, trigger ProcTrigger, source string) {
+	if actor == nil || actor.Type != TypePlayer || len(actor.Procs) == 0 {
+		return
+	}
+	for _, proc := range actor.Procs {
+		if proc.Trigger != trigger {
+			continue
+		}
+		if proc.Source != "" && proc.Source != source {
+			continue
+		}
+		if proc.Chance < 1 && rand.Float64() >= proc.Chance {
+			continue
+		}
+		w.applyProc(actor, target, proc)
+	}
+}
+
+func (w *World) applyProc(actor, target *Entity, proc ProcDef) {
+	switch proc.Effect {
+	case EffectHeal:
+		w.heal(actor, actor, actor.MaxHealth*proc.Value/100, "Proc")
+
+	case EffectRestoreMana:
+		actor.Mana += proc.Value
+		if actor.Mana > actor.MaxMana {
+			actor.Mana = actor.MaxMana
+		}
+
+	case EffectBurningTrail:
+		// Picked up by the charge movement in Update
+		actor.TrailDamage = proc.Value
+		actor.LastTrailX = actor.X
+		actor.LastTrailZ = actor.Z
+
+	case EffectChainFireball:
+		if target == nil {
+			return
+		}
+		w.chainFireball(actor, target, proc.Value)
+
+	case EffectExplode:
+		if target == nil {
+			return
+		}
+		for _, e := range w.Entities {
+			if e == target || e.Type != TypeEnemy || e.State == "DEAD" {
+				continue
+			}
+			dx := e.X - target.X
+			dz := e.Z - target.Z
+			if math.Sqrt(dx*dx+dz*dz) < explodeRadius {
+				w.ApplyDamage(actor, e, DamageSpec{Amount: proc.Value, Type: AbilityDamageType("Explode"), Source: "Explode"})
+			}
+		}
+	}
+}
+
+// chainFireball launches a weaker Fireball from target at the nearest other enemy.
+func (w *World) chainFireball(owner, from *Entity, pct int) {
+	var next *Entity
+	best := chainFireballSeek
+	for _, e := range w.Entities {
+		if e == from || e.Type != TypeEnemy || e.State == "DEAD" {
+			continue
+		}
+		dx := e.X - from.X
+		dz := e.Z - from.Z
+		if d := math.Sqrt(dx*dx + dz*dz); d < best {
+			best = d
+			next = e
+		}
+	}
+	if next == nil {
+		return
+	}
+
+	dx := next.X - from.X
+	dz := next.Z - from.Z
+	dist := math.Sqrt(dx*dx + dz*dz)
+	if dist == 0 {
+		dist = 1
+	}
+	velX := (dx / dist) * 20.0
+	velZ := (dz / dist) * 20.0
+
+	damage := (20 + owner.Stats.Intelligence*2) * pct / 100
+	proj := &Entity{
+		ID:       fmt.Sprintf("proj-%d", rand.Int63()),
+		Type:     TypeProjectile,
+		SubType:  "Fireball",
+		X:        from.X + (dx/dist)*1.5, // Start clear of the enemy that was just hit
+		Y:        1.5,
+		Z:        from.Z + (dz/dist)*1.5,
+		VelX:     velX,
+		VelZ:     velZ,
+		Radius:   2.0,
+		Damage:   damage,
+		OwnerID:  owner.ID,
+		Rotation: math.Atan2(velX, velZ),
+		NoProc:   true,    // Chains do not chain again
+		IgnoreID: from.ID, // Nor hit the enemy they jumped from
+		Element:  AbilityDamageType("Fireball"),
+	}
+	w.Entities[proj.ID] = proj
+}
+
+// dropFireTrail leaves a burning patch behind a charging player.
+func (w *World) dropFireTrail(owner *Entity) {
+	patch := &Entity{
+		ID:         fmt.Sprintf("proj-%d", rand.Int63()),
+		Type:       TypeProjectile,
+		SubType:    "FireTrail",
+		X:          owner.X,
+		Y:          0.1,
+		Z:          owner.Z,
+		Radius:     fireTrailRadius,
+		Damage:     owner.TrailDamage,
+		OwnerID:    owner.ID,
+		ExpireTime: time.Now().Add(fireTrailDuration),
+		NoProc:     true,
+		Element:    AbilityDamageType("FireTrail"),
+	}
+	w.Entities[patch.ID] = patch
+	owner.LastTrailX = owner.X
+	owner.LastTrailZ = owner.Z
+}
+
+// updateFireTrail ticks damage from a stationary fire patch. Returns false once expired.
+func (w *World) updateFireTrail(patch *Entity, enemies []*Entity) bool {
+	if time.Now().After(patch.ExpireTime) {
+		return false
+	}
+	if time.Since(patch.LastTick) < fireTrailTick {
+		return true
+	}
+	patch.LastTick = time.Now()
+
+	owner := w.Entities[patch.OwnerID]
+	for _, target := range enemies {
+		if target.State == "DEAD" {
+			continue
+		}
+		dx := patch.X - target.X
+		dz := patch.Z - target.Z
+		if math.Sqrt(dx*dx+dz*dz) < patch.Radius+0.5 {
+			w.ApplyDamage(owner, target, DamageSpec{Amount: patch.Damage, Type: patch.Element, Source: patch.SubType})
+		}
+	}
+	return true
+}
@@ -0,0 +1,429 @@
+package game
+
+import (
+	"math"
+	"math/rand"
+)
+
+const (
+	InteractRange   = 8.0 // Max distance to talk to an NPC
+	MaxActiveQuests = 10
+)
+
+type ObjectiveKind string
+
+const (
+	ObjectiveKill    ObjectiveKind = "kill"    // Kill Count enemies of Target subtype ("" = any)
+	ObjectiveCollect ObjectiveKind = "collect" // Pick up Count items matching Target (base item, item type or gem type)
+	ObjectiveReach   ObjectiveKind = "reach"   // Walk within Radius of X/Z
+	ObjectiveElite   ObjectiveKind = "elite"   // Defeat Count elites of Target subtype ("" = any)
+)
+
+type QuestObjective struct {
+	Kind   ObjectiveKind `json:"kind"`
+	Target string        `json:"target,omitempty"`
+	Count  int           `json:"count"`
+	X      float64       `json:"x,omitempty"`
+	Z      float64       `json:"z,omitempty"`
+	Radius float64       `json:"radius,omitempty"`
+	Text   string        `json:"text"`
+}
+
+type QuestReward struct {
+	XP         int        `json:"xp"`
+	Gold       int        `json:"gold"`
+	ItemSlot   string     `json:"itemSlot,omitempty"` // Rolls an item for this slot at the player's level
+	ItemRarity ItemRarity `json:"itemRarity,omitempty"`
+}
+
+type QuestDef struct {
+	ID          string           `json:"id"`
+	Name        string           `json:"name"`
+	Giver       string           `json:"giver"` // NPC entity ID
+	MinLevel    int              `json:"minLevel"`
+	Requires    string           `json:"requires,omitempty"` // Quest that must be turned in first
+	Description string           `json:"description"`
+	Objectives  []QuestObjective `json:"objectives"`
+	Reward      QuestReward      `json:"reward"`
+}
+
+// QuestProgress is one accepted quest on a character
+type QuestProgress struct {
+	QuestID  string `json:"questId"`
+	Progress []int  `json:"progress"` // Per objective
+	Complete bool   `json:"complete"` // Ready to turn in
+}
+
+// QuestUpdate is sent through OnEvent when a player's quest progress changes
+type QuestUpdate struct {
+	PlayerID string
+	Quest    QuestProgress
+}
+
+type QuestGiver struct {
+	ID   string
+	Name string
+	X, Z float64
+}
+
+var QuestGivers = []QuestGiver{
+	{ID: "npc-warden", Name: "Warden Hale", X: -6, Z: 4},
+	{ID: "npc-scholar", Name: "Scholar Ysolde", X: 6, Z: -8},
+}
+
+var Quests = []QuestDef{
+	{
+		ID: "bones_of_the_weald", Name: "Bones of the Weald", Giver: "npc-warden", MinLevel: 1,
+		Description: "The dead walk the Iron Weald. Put them back in the ground.",
+		Objectives:  []QuestObjective{{Kind: ObjectiveKill, Target: "Skeleton", Count: 10, Text: "Destroy Skeletons"}},
+		Reward:      QuestReward{XP: 200, Gold: 100},
+	},
+	{
+		ID: "scout_the_sands", Name: "Scout the Sands", Giver: "npc-warden", MinLevel: 5, Requires: "bones_of_the_weald",
+		Description: "Find the old watchtower at the edge of the Shifting Sands and report what you see.",
+		Objectives:  []QuestObjective{{Kind: ObjectiveReach, X: 200, Z: 0, Radius: 15, Count: 1, Text: "Reach the Sands watchtower"}},
+		Reward:      QuestReward{XP: 300, Gold: 150},
+	},
+	{
+		ID: "imp_embers", Name: "Embers of the Mirage", Giver: "npc-warden", MinLevel: 8, Requires: "scout_the_sands",
+		Description: "Imps feed the mirage that binds the Sands. Thin their numbers.",
+		Objectives:  []QuestObjective{{Kind: ObjectiveKill, Target: "Imp", Count: 15, Text: "Slay Imps"}},
+		Reward:      QuestReward{XP: 600, Gold: 300, ItemSlot: "offHand", ItemRarity: RarityRare},
+	},
+	{
+		ID: "elite_hunter", Name: "Champion's Bounty", Giver: "npc-warden", MinLevel: 10,
+		Description: "Something stronger leads the monsters. Bring it down.",
+		Objectives:  []QuestObjective{{Kind: ObjectiveElite, Count: 1, Text: "Defeat an Elite"}},
+		Reward:      QuestReward{XP: 1000, Gold: 500, ItemSlot: "mainHand", ItemRarity: RarityRare},
+	},
+	{
+		ID: "shards_of_memory", Name: "Shards of Memory", Giver: "npc-scholar", MinLevel: 1,
+		Description: "Gems hold echoes of the realms before the Sundering. Bring me any you find.",
+		Objectives:  []QuestObjective{{Kind: ObjectiveCollect, Target: string(ItemGem), Count: 3, Text: "Collect gems"}},
+		Reward:      QuestReward{XP: 250, Gold: 300},
+	},
+	{
+		ID: "tomes_of_the_well", Name: "Tomes of the Well", Giver: "npc-scholar", MinLevel: 12, Requires: "shards_of_memory",
+		Description: "The drowned scholars of the Abyssal Well carried their notes everywhere. Recover them.",
+		Objectives:  []QuestObjective{{Kind: ObjectiveCollect, Target: "Spell Tome", Count: 2, Text: "Recover Spell Tomes"}},
+		Reward:      QuestReward{XP: 900, Gold: 400, ItemSlot: "head", ItemRarity: RarityRare},
+	},
+	{
+		ID: "spire_ascent", Name: "The Silent Spire", Giver: "npc-scholar", MinLevel: 18, Requires: "tomes_of_the_well",
+		Description: "The Crystalline Spire has gone quiet. Find out why, and survive its guardians.",
+		Objectives: []QuestObjective{
+			{Kind: ObjectiveReach, X: 0, Z: 400, Radius: 20, Count: 1, Text: "Reach the Crystalline Spire"},
+			{Kind: ObjectiveKill, Target: "Construct", Count: 10, Text: "Disable Constructs"},
+		},
+		Reward: QuestReward{XP: 3000, Gold: 1500, ItemSlot: "chest", ItemRarity: RarityLegendary},
+	},
+}
+
+var questByID = func() map[string]*QuestDef {
+	m := make(map[string]*QuestDef, len(Quests))
+	for i := range Quests {
+		m[Quests[i].ID] = &Quests[i]
+	}
+	return m
+}()
+
+func GetQuest(id string) *QuestDef {
+	return questByID[id]
+}
+
+func (w *World) spawnQuestGivers() {
+	for _, g := range QuestGivers {
+		w.AddEntity(&Entity{
+			ID:      g.ID,
+			Name:    g.Name,
+			Type:    TypeNPC,
+			SubType: "QuestGiver",
+			X:       g.X,
+			Z:       g.Z,
+			State:   "IDLE",
+		})
+	}
+}
+
+// nearNPC reports whether the player is close enough to talk to an NPC.
+// Caller must hold the lock.
+func (w *World) nearNPC(player *Entity, npcID string) bool {
+	npc, ok := w.Entities[npcID]
+	if !ok || npc.Type != TypeNPC {
+		return false
+	}
+	dx := player.X - npc.X
+	dz := player.Z - npc.Z
+	return math.Sqrt(dx*dx+dz*dz) <= InteractRange
+}
+
+func (e *Entity) activeQuest(questID string) int {
+	for i := range e.Quests {
+		if e.Quests[i].QuestID == questID {
+			return i
+		}
+	}
+	return -1
+}
+
+// canAcceptQuest checks level, prerequisites and whether the quest is already taken.
+func (e *Entity) canAcceptQuest(def *QuestDef) bool {
+	if e.Level < def.MinLevel || e.CompletedQuests[def.ID] || e.activeQuest(def.ID) >= 0 {
+		return false
+	}
+	return def.Requires == "" || e.CompletedQuests[def.Requires]
+}
+
+func (w *World) PerformQuestAccept(playerID, questID string) (*Entity, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	player, ok := w.Entities[playerID]
+	if !ok || player.State == "DEAD" {
+		return nil, false
+	}
+	def := GetQuest(questID)
+	if def == nil || !w.nearNPC(player, def.Giver) {
+		return nil, false
+	}
+	if !w.acceptQuest(player, def) {
+		return nil, false
+	}
+	return player, true
+}
+
+// acceptQuest adds a quest to the player's log. Caller must hold the lock.
+func (w *World) acceptQuest(player *Entity, def *QuestDef) bool {
+	if !player.canAcceptQuest(def) || len(player.Quests) >= MaxActiveQuests {
+		return false
+	}
+
+	player.Quests = append(player.Quests, QuestProgress{
+		QuestID:  def.ID,
+		Progress: make([]int, len(def.Objectives)),
+	})
+	// Already standing at a reach objective counts immediately
+	w.questReach(player)
+	return true
+}
+
+func (w *World) PerformQuestAbandon(playerID, questID string) (*Entity, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	player, ok := w.Entities[playerID]
+	if !ok {
+		return nil, false
+	}
+	i := player.activeQuest(questID)
+	if i < 0 {
+		return nil, false
+	}
+	player.Quests = append(player.Quests[:i], player.Quests[i+1:]...)
+	return player, true
+}
+
+// PerformQuestTurnIn hands a completed quest back to its giver for the reward.
+func (w *World) PerformQuestTurnIn(playerID, questID string) (*Entity, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	player, ok := w.Entities[playerID]
+	if !ok || player.State == "DEAD" {
+		return nil, false
+	}
+	def := GetQuest(questID)
+	if def == nil || !w.nearNPC(player, def.Giver) {
+		return nil, false
+	}
+	if !w.turnInQuest(player, def) {
+		return nil, false
+	}
+	return player, true
+}
+
+// turnInQuest completes a finished quest and grants its reward. Caller must hold the lock.
+func (w *World) turnInQuest(player *Entity, def *QuestDef) bool {
+	i := player.activeQuest(def.ID)
+	if i < 0 || !player.Quests[i].Complete {
+		return false
+	}
+
+	var item *Item
+	if def.Reward.ItemSlot != "" {
+		if len(player.Inventory) >= MaxInventorySize {
+			return false
+		}
+		item = questRewardItem(def.Reward.ItemSlot, def.Reward.ItemRarity, player.Level)
+	}
+
+	player.Quests = append(player.Quests[:i], player.Quests[i+1:]...)
+	if player.CompletedQuests == nil {
+		player.CompletedQuests = make(map[string]bool)
+	}
+	player.CompletedQuests[def.ID] = true
+
+	player.earnGold(def.Reward.Gold)
+	w.awardExperience(player, def.Reward.XP, "quest")
+	if item != nil {
+		player.Inventory = append(player.Inventory, *item)
+	}
+	return true
+}
+
+// questRewardItem rolls a reward of a fixed rarity for a slot.
+func questRewardItem(slot string, rarity ItemRarity, level int) *Item {
+	var candidates []BaseItem
+	for _, b := range BaseItems {
+		if b.Slot == slot {
+			candidates = append(candidates, b)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	if special := rollSpecialItem(rarity, level, slot); special != nil {
+		return special
+	}
+	base := candidates[rand.Intn(len(candidates))]
+	return createItem(base, rarity, RarityMultiplier[rarity], rarityAffixCount[rarity], level)
+}
+
+// advanceQuests adds progress to every active objective accepted by match.
+// Caller must hold the lock.
+func (w *World) advanceQuests(player *Entity, match func(obj QuestObjective) int) {
+	for i := range player.Quests {
+		q := &player.Quests[i]
+		def := GetQuest(q.QuestID)
+		if def == nil || q.Complete {
+			continue
+		}
+
+		changed := false
+		for j, obj := range def.Objectives {
+			if j >= len(q.Progress) || q.Progress[j] >= obj.Count {
+				continue
+			}
+			if n := match(obj); n > 0 {
+				q.Progress[j] = int(math.Min(float64(obj.Count), float64(q.Progress[j]+n)))
+				changed = true
+			}
+		}
+		if !changed {
+			continue
+		}
+
+		q.Complete = true
+		for j, obj := range def.Objectives {
+			if q.Progress[j] < obj.Count {
+				q.Complete = false
+				break
+			}
+		}
+
+		if w.OnEvent != nil {
+			update := QuestUpdate{PlayerID: player.ID, Quest: *q}
+			update.Quest.Progress = append([]int(nil), q.Progress...)
+			w.OnEvent("quest_update", update)
+		}
+	}
+}
+
+// questKill counts kill and elite objectives. Caller must hold the lock.
+func (w *World) questKill(player, target *Entity) {
+	if len(player.Quests) == 0 {
+		return
+	}
+	w.advanceQuests(player, func(obj QuestObjective) int {
+		if obj.Target != "" && obj.Target != target.SubType {
+			return 0
+		}
+		if obj.Kind == ObjectiveKill || (obj.Kind == ObjectiveElite && target.IsElite()) {
+			return 1
+		}
+		return 0
+	})
+}
+
+// questCollect counts collect objectives for a picked up item. Caller must hold the lock.
+func (w *World) questCollect(player *Entity, item Item) {
+	if len(player.Quests) == 0 {
+		return
+	}
+	w.advanceQuests(player, func(obj QuestObjective) int {
+		if obj.Kind != ObjectiveCollect {
+			return 0
+		}
+		if obj.Target == item.Base || obj.Target == string(item.Type) || obj.Target == item.GemType {
+			return 1
+		}
+		return 0
+	})
+}
+
+// questReach completes reach objectives the player is standing in. Caller must hold the lock.
+func (w *World) questReach(player *Entity) {
+	if len(player.Quests) == 0 {
+		return
+	}
+	w.advanceQuests(player, func(obj QuestObjective) int {
+		if obj.Kind != ObjectiveReach {
+			return 0
+		}
+		dx := player.X - obj.X
+		dz := player.Z - obj.Z
+		if math.Sqrt(dx*dx+dz*dz) <= obj.Radius {
+			return obj.Count
+		}
+		return 0
+	})
+}
+
+// QuestLog is a player's view of their quests
+type QuestLog struct {
+	Active    []QuestLogEntry `json:"active"`
+	Available []QuestDef      `json:"available"` // Can be accepted from their giver now
+	Completed []string        `json:"completed"`
+}
+
+type QuestLogEntry struct {
+	Quest    QuestDef `json:"quest"`
+	Progress []int    `json:"progress"`
+	Complete bool     `json:"complete"`
+}
+
+func (w *World) GetQuestLog(playerID string) (*QuestLog, bool) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	player, ok := w.Entities[playerID]
+	if !ok {
+		return nil, false
+	}
+
+	log := &QuestLog{
+		Active:    make([]QuestLogEntry, 0, len(player.Quests)),
+		Available: make([]QuestDef, 0),
+		Completed: make([]string, 0, len(player.CompletedQuests)),
+	}
+	for _, q := range player.Quests {
+		if def := GetQuest(q.QuestID); def != nil {
+			log.Active = append(log.Active, QuestLogEntry{
+				Quest:    *def,
+				Progress: append([]int(nil), q.Progress...),
+				Complete: q.Complete,
+			})
+		}
+	}
+	for i := range Quests {
+		if player.canAcceptQuest(&Quests[i]) {
+			log.Available = append(log.Available, Quests[i])
+		}
+	}
+	for _, def := range Quests {
+		if player.CompletedQuests[def.ID] {
+			log.Completed = append(log.Completed, def.ID)
+		}
+	}
+	return log, true
+}
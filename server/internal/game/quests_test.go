@@ -0,0 +1,113 @@
+package game
+
+import (
+	"testing"
+)
+
+func newQuestTestPlayer(w *World, giverID string) *Entity {
+	giver := w.Entities[giverID]
+	player := &Entity{
+		ID:            "p1",
+		Type:          TypePlayer,
+		SubType:       "Fighter",
+		Level:         1,
+		MaxExperience: 100,
+		X:             giver.X,
+		Z:             giver.Z,
+		BaseStats:     Stats{Vitality: 10},
+	}
+	player.RecalculateStats()
+	w.Entities[player.ID] = player
+	return player
+}
+
+func TestQuestDefinitions(t *testing.T) {
+	for _, q := range Quests {
+		found := false
+		for _, g := range QuestGivers {
+			if g.ID == q.Giver {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("Quest %q has unknown giver %q", q.ID, q.Giver)
+		}
+		if q.Requires != "" && GetQuest(q.Requires) == nil {
+			t.Errorf("Quest %q requires unknown quest %q", q.ID, q.Requires)
+		}
+	}
+}
+
+func TestKillQuestFlow(t *testing.T) {
+	w := NewWorld()
+	player := newQuestTestPlayer(w, "npc-warden")
+
+	if _, ok := w.PerformQuestAccept(player.ID, "scout_the_sands"); ok {
+		t.Fatal("Accepted a quest without its prerequisite")
+	}
+	if _, ok := w.PerformQuestAccept(player.ID, "bones_of_the_weald"); !ok {
+		t.Fatal("Accept failed")
+	}
+
+	for i := 0; i < 10; i++ {
+		w.questKill(player, &Entity{ID: "Imp-1", SubType: "Imp"})
+	}
+	if player.Quests[0].Progress[0] != 0 {
+		t.Fatal("Wrong subtype counted towards the quest")
+	}
+	for i := 0; i < 12; i++ {
+		w.questKill(player, &Entity{ID: "Skeleton-1", SubType: "Skeleton"})
+	}
+	if player.Quests[0].Progress[0] != 10 || !player.Quests[0].Complete {
+		t.Fatalf("Progress = %v complete=%v", player.Quests[0].Progress, player.Quests[0].Complete)
+	}
+
+	if _, ok := w.PerformQuestTurnIn(player.ID, "bones_of_the_weald"); !ok {
+		t.Fatal("Turn in failed")
+	}
+	if player.Gold != 100 || player.Level < 2 {
+		t.Errorf("Reward not granted: gold %d level %d", player.Gold, player.Level)
+	}
+	if !player.CompletedQuests["bones_of_the_weald"] || len(player.Quests) != 0 {
+		t.Error("Quest not moved to completed")
+	}
+	if _, ok := w.PerformQuestAccept(player.ID, "bones_of_the_weald"); ok {
+		t.Error("Accepted a completed quest again")
+	}
+}
+
+func TestCollectAndReachQuests(t *testing.T) {
+	w := NewWorld()
+	player := newQuestTestPlayer(w, "npc-scholar")
+
+	if _, ok := w.PerformQuestAccept(player.ID, "shards_of_memory"); !ok {
+		t.Fatal("Accept failed")
+	}
+	for i := 0; i < 3; i++ {
+		gem := NewGem("ruby", 1)
+		loot := &Entity{ID: "loot-gem", Type: TypeLoot, X: player.X, Z: player.Z, LootItem: gem}
+		w.Entities[loot.ID] = loot
+		if _, ok := w.PerformPickup(player.ID, loot.ID); !ok {
+			t.Fatal("Pickup failed")
+		}
+	}
+	if !player.Quests[0].Complete {
+		t.Fatalf("Collect quest not complete: %v", player.Quests[0].Progress)
+	}
+	if _, ok := w.PerformQuestAbandon(player.ID, "shards_of_memory"); !ok || len(player.Quests) != 0 {
+		t.Fatal("Abandon failed")
+	}
+
+	// Reach objective via movement
+	player.Level = 5
+	player.CompletedQuests = map[string]bool{"bones_of_the_weald": true}
+	warden := w.Entities["npc-warden"]
+	w.MovePlayer(player.ID, warden.X, 0, warden.Z, 0, "", 0)
+	if _, ok := w.PerformQuestAccept(player.ID, "scout_the_sands"); !ok {
+		t.Fatal("Accept failed")
+	}
+	w.MovePlayer(player.ID, 195, 0, 5, 0, "MOVING", 0)
+	if !player.Quests[0].Complete {
+		t.Fatal("Reach objective not completed by movement")
+	}
+}
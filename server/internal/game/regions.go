@@ -0,0 +1,56 @@
+package game
+
+import (
+	"fmt"
+)
+
+// Kills in a realm's zone (by all players together) needed to restore it
+const RegionRestoreKills = 500
+
+// RegionStatus is the restoration progress of one realm
+type RegionStatus struct {
+	Name     string `json:"name"`
+	Kills    int    `json:"kills"`
+	Required int    `json:"required"`
+	Restored bool   `json:"restored"`
+}
+
+// recordRegionKill advances restoration of the zone the enemy died in.
+// Caller must hold the lock.
+func (w *World) recordRegionKill(target *Entity) {
+	zone := ZoneAt(target.X, target.Z)
+	if zone == nil || w.RestoredRegions[zone.Name] {
+		return
+	}
+	w.RegionKills[zone.Name]++
+	if w.RegionKills[zone.Name] < RegionRestoreKills {
+		return
+	}
+	w.RestoredRegions[zone.Name] = true
+
+	// Everyone fighting in the realm when it turns shares the achievement
+	for _, e := range w.Entities {
+		if e.Type == TypePlayer && ZoneAt(e.X, e.Z) == zone {
+			w.unlockAchievement(e, AchievementRestoreRegion)
+		}
+	}
+	if w.OnEvent != nil {
+		w.OnEvent("region_restored", fmt.Sprintf("The %s has been restored! Harmony returns to the realm.", zone.Name))
+	}
+}
+
+func (w *World) GetRegions() []RegionStatus {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	out := make([]RegionStatus, 0, len(Zones))
+	for _, zone := range Zones {
+		out = append(out, RegionStatus{
+			Name:     zone.Name,
+			Kills:    w.RegionKills[zone.Name],
+			Required: RegionRestoreKills,
+			Restored: w.RestoredRegions[zone.Name],
+		})
+	}
+	return out
+}
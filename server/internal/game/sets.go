@@ -0,0 +1,172 @@
+package game
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+const RaritySet ItemRarity = "Set"
+
+type SetPiece struct {
+	Name  string
+	Base  string // BaseItem name
+	Stats map[string]int
+}
+
+type SetBonus struct {
+	Pieces int // Equipped pieces required
+	Stats  map[string]int
+	Proc   *ProcDef
+	Text   string
+}
+
+type ItemSet struct {
+	ID       string
+	Name     string
+	MinLevel int
+	Pieces   []SetPiece
+	Bonuses  []SetBonus
+}
+
+var ItemSets = []ItemSet{
+	{
+		ID: "harmonizer", Name: "Harmonizer's Regalia", MinLevel: 5,
+		Pieces: []SetPiece{
+			{Name: "Harmonizer's Circlet", Base: "Silk Hood", Stats: map[string]int{"wisdom": 8}},
+			{Name: "Harmonizer's Vestments", Base: "Robes", Stats: map[string]int{"intelligence": 8}},
+			{Name: "Harmonizer's Leggings", Base: "Silk Skirt", Stats: map[string]int{"vitality": 8}},
+			{Name: "Harmonizer's Steps", Base: "Sandals", Stats: map[string]int{StatMoveSpeed: 5}},
+			{Name: "Harmonizer's Rod", Base: "Wooden Staff", Stats: map[string]int{"intelligence": 10}},
+			{Name: "Harmonizer's Codex", Base: "Spell Tome", Stats: map[string]int{"wisdom": 10}},
+		},
+		Bonuses: []SetBonus{
+			{Pieces: 2, Stats: map[string]int{"intelligence": 15}, Text: "+15 Intelligence"},
+			{Pieces: 4, Stats: map[string]int{StatFireResist: 15, StatWaterResist: 15, StatEarthResist: 15, StatAirResist: 15}, Text: "+15% All Resistances"},
+			{Pieces: 6, Proc: &ProcDef{Trigger: ProcOnAbility, Effect: EffectRestoreMana, Chance: 1, Value: 20}, Text: "Abilities restore 20 mana"},
+		},
+	},
+	{
+		ID: "iron_weald", Name: "Bulwark of the Iron Weald", MinLevel: 10,
+		Pieces: []SetPiece{
+			{Name: "Weald Helm", Base: "Iron Helm", Stats: map[string]int{"vitality": 10}},
+			{Name: "Weald Plate", Base: "Plate Mail", Stats: map[string]int{"strength": 10}},
+			{Name: "Weald Greaves", Base: "Plate Greaves", Stats: map[string]int{"vitality": 10}},
+			{Name: "Weald Treads", Base: "Iron Boots", Stats: map[string]int{StatEarthResist: 10}},
+			{Name: "Weald Cleaver", Base: "Iron Sword", Stats: map[string]int{"strength": 12}},
+			{Name: "Weald Aegis", Base: "Wooden Shield", Stats: map[string]int{StatDefense: 8}},
+		},
+		Bonuses: []SetBonus{
+			{Pieces: 2, Stats: map[string]int{"vitality": 20}, Text: "+20 Vitality"},
+			{Pieces: 4, Stats: map[string]int{StatDefense: 25, StatLifeOnHit: 8}, Text: "+25 Defense, +8 Life on Hit"},
+			{Pieces: 6, Proc: &ProcDef{Trigger: ProcOnKill, Effect: EffectExplode, Chance: 1, Value: 80}, Text: "Enemies you kill burst for 80 damage"},
+		},
+	},
+}
+
+var setByID = func() map[string]*ItemSet {
+	m := make(map[string]*ItemSet, len(ItemSets))
+	for i := range ItemSets {
+		m[ItemSets[i].ID] = &ItemSets[i]
+	}
+	return m
+}()
+
+func GetItemSet(id string) *ItemSet {
+	return setByID[id]
+}
+
+// GenerateSetPiece rolls a random piece from any set that can drop at this level
+// (optionally restricted to a slot). Returns nil if none qualify.
+func GenerateSetPiece(level int, slot string) *Item {
+	type candidate struct {
+		set   *ItemSet
+		piece SetPiece
+	}
+	var candidates []candidate
+	for i := range ItemSets {
+		set := &ItemSets[i]
+		if set.MinLevel > level {
+			continue
+		}
+		for _, piece := range set.Pieces {
+			if base, ok := findBaseItem(piece.Base); ok && (slot == "" || base.Slot == slot) {
+				candidates = append(candidates, candidate{set, piece})
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	c := candidates[rand.Intn(len(candidates))]
+	return createSetPiece(c.set, c.piece, level)
+}
+
+func createSetPiece(set *ItemSet, piece SetPiece, level int) *Item {
+	base, ok := findBaseItem(piece.Base)
+	if !ok {
+		return nil
+	}
+	multiplier := RarityMultiplier[RarityRare]
+	baseVal := int(float64(base.BaseValue) * (1.0 + float64(level)*0.15) * multiplier)
+
+	item := &Item{
+		ID:       fmt.Sprintf("item-%d", rand.Int63()),
+		Name:     piece.Name,
+		Type:     base.Type,
+		Rarity:   RaritySet,
+		Slot:     base.Slot,
+		Level:    level,
+		Value:    level * 10 * int(multiplier),
+		Base:     base.Name,
+		Implicit: map[string]int{base.BaseStat: baseVal},
+		SetID:    set.ID,
+		Sockets:  rollSockets(RaritySet),
+	}
+	for k, v := range piece.Stats {
+		item.Implicit[k] += v
+	}
+	item.MaxDurability = maxDurability(level)
+	item.Durability = item.MaxDurability
+	item.RebuildStats()
+
+	lines := []string{set.Name}
+	for _, b := range set.Bonuses {
+		lines = append(lines, fmt.Sprintf("(%d) %s", b.Pieces, b.Text))
+	}
+	item.Description = strings.Join(lines, "\n")
+	return item
+}
+
+// activeSetBonuses returns the set bonuses unlocked by the given equipment,
+// along with the equipped piece count per set.
+func activeSetBonuses(equipment map[string]Item) ([]SetBonus, map[string]int) {
+	counts := make(map[string]int)
+	for _, item := range equipment {
+		if item.SetID != "" && !item.Broken() {
+			counts[item.SetID]++
+		}
+	}
+
+	// Iterate sets in a stable order so proc order is deterministic
+	ids := make([]string, 0, len(counts))
+	for id := range counts {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var bonuses []SetBonus
+	for _, id := range ids {
+		set := GetItemSet(id)
+		if set == nil {
+			continue
+		}
+		for _, b := range set.Bonuses {
+			if counts[id] >= b.Pieces {
+				bonuses = append(bonuses, b)
+			}
+		}
+	}
+	return bonuses, counts
+}
@@ -0,0 +1,98 @@
+package game
+
+import (
+	"testing"
+)
+
+func TestSetBonusThresholds(t *testing.T) {
+	set := GetItemSet("iron_weald")
+	e := &Entity{Level: 10, BaseStats: Stats{Strength: 10, Dexterity: 10, Intelligence: 10, Wisdom: 10, Vitality: 10}}
+	e.Equipment = make(map[string]Item)
+
+	e.RecalculateStats()
+	baseHealth := e.MaxHealth
+
+	// One piece: no bonus beyond the piece's own stats
+	head := createSetPiece(set, set.Pieces[0], 10)
+	e.Equipment[head.Slot] = *head
+	e.RecalculateStats()
+	onePiece := e.MaxHealth
+	if onePiece != baseHealth+head.Stats["vitality"]*10 {
+		t.Errorf("MaxHealth with 1 piece = %d, want %d", onePiece, baseHealth+head.Stats["vitality"]*10)
+	}
+
+	// Two pieces: +20 Vitality bonus
+	chest := createSetPiece(set, set.Pieces[1], 10)
+	e.Equipment[chest.Slot] = *chest
+	e.RecalculateStats()
+	if e.Stats.Vitality != 10+head.Stats["vitality"]+20 {
+		t.Errorf("Vitality with 2 pieces = %d, want %d", e.Stats.Vitality, 10+head.Stats["vitality"]+20)
+	}
+	if e.SetPieces["iron_weald"] != 2 {
+		t.Errorf("SetPieces = %v, want 2", e.SetPieces)
+	}
+	if len(e.Procs) != 0 {
+		t.Errorf("Procs active before 6 pieces: %v", e.Procs)
+	}
+
+	// Six pieces: on-kill proc
+	for _, piece := range set.Pieces[2:] {
+		item := createSetPiece(set, piece, 10)
+		e.Equipment[item.Slot] = *item
+	}
+	e.RecalculateStats()
+	if len(e.Procs) != 1 || e.Procs[0].Effect != EffectExplode {
+		t.Errorf("Procs with 6 pieces = %v, want explode", e.Procs)
+	}
+}
+
+func TestChainFireballProc(t *testing.T) {
+	w := NewWorld()
+	w.Entities = make(map[string]*Entity)
+
+	staff := createUnique(GetUnique("emberheart"), 10)
+	player := &Entity{ID: "player-1", Type: TypePlayer, State: "IDLE", Level: 10,
+		BaseStats: Stats{Intelligence: 10, Vitality: 10},
+		Equipment: map[string]Item{staff.Slot: *staff}}
+	player.RecalculateStats()
+	player.Procs[0].Chance = 1 // Make the roll deterministic
+
+	first := &Entity{ID: "enemy-1", Type: TypeEnemy, State: "IDLE", X: 100, Health: 1000, MaxHealth: 1000}
+	second := &Entity{ID: "enemy-2", Type: TypeEnemy, State: "IDLE", X: 115, Health: 1000, MaxHealth: 1000} // Beyond splash range of enemy-1
+	w.Entities[player.ID] = player
+	w.Entities[first.ID] = first
+	w.Entities[second.ID] = second
+
+	w.triggerProcs(player, first, ProcOnHit, "Fireball")
+
+	chains := 0
+	var chain *Entity
+	for _, e := range w.Entities {
+		if e.Type == TypeProjectile && e.SubType == "Fireball" && e.NoProc {
+			chains++
+			chain = e
+			if e.VelX <= 0 {
+				t.Errorf("Chain fireball not heading towards enemy-2: vel=%f", e.VelX)
+			}
+		}
+	}
+	if chains != 1 {
+		t.Fatalf("Chain fireballs spawned = %d, want 1", chains)
+	}
+
+	// Other sources do not trigger it
+	w.triggerProcs(player, first, ProcOnHit, "Dagger")
+	if len(w.Entities) != 4 {
+		t.Errorf("Dagger hit triggered a Fireball-only proc")
+	}
+
+	// The chain flies past its source, even one stepping after it, and lands
+	// on the next enemy
+	first.X += 0.5
+	for i := 0; i < 20 && w.Entities[chain.ID] != nil; i++ {
+		w.Update(0.05)
+	}
+	if first.Health != 1000 || second.Health >= 1000 {
+		t.Errorf("Chain hit source %d / next %d, want only the next enemy", first.Health, second.Health)
+	}
+}
@@ -0,0 +1,104 @@
+package game
+
+const (
+	// Carried inventory cap (bag slots)
+	MaxInventorySize = 20
+
+	// Account stash layout
+	StashTabCount = 4
+	StashTabSize  = 40
+)
+
+// Stash is the account-wide bank. It is shared by every character on the
+// account and can only be accessed from inside town.
+type Stash struct {
+	Tabs [][]Item `json:"tabs"`
+}
+
+func NewStash() *Stash {
+	s := &Stash{}
+	s.ensureTabs()
+	return s
+}
+
+// ensureTabs pads the stash to StashTabCount tabs (older saves may have fewer).
+func (s *Stash) ensureTabs() {
+	for len(s.Tabs) < StashTabCount {
+		s.Tabs = append(s.Tabs, make([]Item, 0))
+	}
+}
+
+func (s *Stash) Copy() *Stash {
+	c := &Stash{Tabs: make([][]Item, len(s.Tabs))}
+	for i, tab := range s.Tabs {
+		c.Tabs[i] = make([]Item, len(tab))
+		copy(c.Tabs[i], tab)
+	}
+	return c
+}
+
+// SafeZoneHalf is the half width of the town square
+const SafeZoneHalf = 50.0
+
+// InSafeZone reports whether a position is inside the town (-50 to 50 on both axes).
+func InSafeZone(x, z float64) bool {
+	return x > -SafeZoneHalf && x < SafeZoneHalf && z > -SafeZoneHalf && z < SafeZoneHalf
+}
+
+func (w *World) PerformStashDeposit(playerID, itemID string, tab int) (*Entity, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	player, ok := w.Entities[playerID]
+	if !ok || player.State == "DEAD" || player.Stash == nil {
+		return nil, false
+	}
+	if !InSafeZone(player.X, player.Z) {
+		return nil, false
+	}
+	if tab < 0 || tab >= StashTabCount {
+		return nil, false
+	}
+
+	player.Stash.ensureTabs()
+	if len(player.Stash.Tabs[tab]) >= StashTabSize {
+		return nil, false
+	}
+
+	item, ok := player.removeInventoryItem(itemID)
+	if !ok {
+		return nil, false
+	}
+	player.Stash.Tabs[tab] = append(player.Stash.Tabs[tab], item)
+	return player, true
+}
+
+func (w *World) PerformStashWithdraw(playerID, itemID string, tab int) (*Entity, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	player, ok := w.Entities[playerID]
+	if !ok || player.State == "DEAD" || player.Stash == nil {
+		return nil, false
+	}
+	if !InSafeZone(player.X, player.Z) {
+		return nil, false
+	}
+	if tab < 0 || tab >= len(player.Stash.Tabs) {
+		return nil, false
+	}
+	if len(player.Inventory) >= MaxInventorySize {
+		return nil, false
+	}
+
+	items := player.Stash.Tabs[tab]
+	for i := range items {
+		if items[i].ID == itemID {
+			player.Inventory = append(player.Inventory, items[i])
+			// Keep tab order stable for the UI
+			player.Stash.Tabs[tab] = append(items[:i], items[i+1:]...)
+			return player, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,55 @@
+package game
+
+import (
+	"testing"
+)
+
+func newStashPlayer(x, z float64) *Entity {
+	return &Entity{
+		ID:        "player-1",
+		Type:      TypePlayer,
+		X:         x,
+		Z:         z,
+		State:     "IDLE",
+		Inventory: []Item{{ID: "item-1", Name: "Iron Sword"}},
+		Stash:     NewStash(),
+	}
+}
+
+func TestStashDepositWithdraw(t *testing.T) {
+	w := NewWorld()
+	p := newStashPlayer(0, 0)
+	w.AddEntity(p)
+
+	if _, ok := w.PerformStashDeposit(p.ID, "item-1", 1); !ok {
+		t.Fatal("Deposit in town failed")
+	}
+	if len(p.Inventory) != 0 || len(p.Stash.Tabs[1]) != 1 {
+		t.Fatalf("Item not moved to stash: inv=%d tab=%d", len(p.Inventory), len(p.Stash.Tabs[1]))
+	}
+
+	// Wrong tab
+	if _, ok := w.PerformStashWithdraw(p.ID, "item-1", 0); ok {
+		t.Error("Withdraw from wrong tab succeeded")
+	}
+
+	if _, ok := w.PerformStashWithdraw(p.ID, "item-1", 1); !ok {
+		t.Fatal("Withdraw in town failed")
+	}
+	if len(p.Inventory) != 1 || len(p.Stash.Tabs[1]) != 0 {
+		t.Fatalf("Item not moved back to inventory: inv=%d tab=%d", len(p.Inventory), len(p.Stash.Tabs[1]))
+	}
+}
+
+func TestStashOutsideTown(t *testing.T) {
+	w := NewWorld()
+	p := newStashPlayer(100, 100)
+	w.AddEntity(p)
+
+	if _, ok := w.PerformStashDeposit(p.ID, "item-1", 0); ok {
+		t.Error("Deposit outside town succeeded")
+	}
+	if _, ok := w.PerformStashDeposit(p.ID, "item-1", StashTabCount); ok {
+		t.Error("Deposit into invalid tab succeeded")
+	}
+}
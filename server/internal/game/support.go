@@ -0,0 +1,218 @@
+package game
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	HealThreatPercent = 50 // Threat per point healed, on enemies fighting the ally
+	AssistXPPercent   = 50 // Share of kill XP for other players on the threat table
+	AssistRange       = 60.0
+	ResurrectHealth   = 0.3 // Fraction of max health restored
+)
+
+type SupportEffect string
+
+const (
+	SupportHeal      SupportEffect = "heal"
+	SupportAreaHeal  SupportEffect = "area_heal"
+	SupportShield    SupportEffect = "shield"
+	SupportResurrect SupportEffect = "resurrect"
+)
+
+// SupportAbility is a friendly-target ability
+type SupportAbility struct {
+	ID       string
+	Name     string
+	Class    string
+	Effect   SupportEffect
+	ManaCost int
+	Cooldown time.Duration
+	Range    float64 // Max distance to the targeted ally
+	Radius   float64 // Area heals: allies within this distance of the caster
+	Base     int     // Heal or shield amount before Wisdom scaling
+	PerWis   int
+}
+
+var SupportAbilities = []SupportAbility{
+	{ID: "heal", Name: "Healing Light", Class: "Cleric", Effect: SupportHeal, ManaCost: 25, Cooldown: 2 * time.Second, Range: 25, Base: 30, PerWis: 3},
+	{ID: "mass_heal", Name: "Circle of Mending", Class: "Cleric", Effect: SupportAreaHeal, ManaCost: 50, Cooldown: 8 * time.Second, Radius: 15, Base: 20, PerWis: 2},
+	{ID: "shield", Name: "Aegis", Class: "Cleric", Effect: SupportShield, ManaCost: 35, Cooldown: 12 * time.Second, Range: 25, Base: 40, PerWis: 4},
+	{ID: "resurrect", Name: "Resurrection", Class: "Cleric", Effect: SupportResurrect, ManaCost: 80, Cooldown: 60 * time.Second, Range: 10},
+}
+
+func GetSupportAbility(id string) *SupportAbility {
+	for i := range SupportAbilities {
+		if SupportAbilities[i].ID == id {
+			return &SupportAbilities[i]
+		}
+	}
+	return nil
+}
+
+// supportAmount scales a heal or shield with the caster's Wisdom.
+func (e *Entity) supportAmount(def *SupportAbility) int {
+	return def.Base + e.Stats.Wisdom*def.PerWis
+}
+
+// supportTarget validates the ally an ability is aimed at. An empty targetID
+// means the caster. Caller must hold the lock.
+func (w *World) supportTarget(caster *Entity, def *SupportAbility, targetID string) (*Entity, bool) {
+	if targetID == "" {
+		targetID = caster.ID
+	}
+	target, ok := w.Entities[targetID]
+	if !ok || target.Type != TypePlayer {
+		return nil, false
+	}
+	if def.Effect == SupportResurrect {
+		if target == caster || target.State != "DEAD" {
+			return nil, false
+		}
+	} else if target.State == "DEAD" {
+		return nil, false
+	}
+
+	dx := target.X - caster.X
+	dz := target.Z - caster.Z
+	if math.Sqrt(dx*dx+dz*dz) > def.Range {
+		return nil, false
+	}
+	return target, true
+}
+
+// healAlly heals a player and credits the healer with threat. Caller must hold the lock.
+func (w *World) healAlly(healer, ally *Entity, amount int, name string) {
+	healed := w.heal(healer, ally, amount, name)
+	if healed <= 0 {
+		return
+	}
+	// Enemies fighting the ally notice whoever keeps them alive
+	threat := healed * HealThreatPercent / 100
+	for _, e := range w.Entities {
+		if e.Type == TypeEnemy && e.State != "DEAD" && e.Threat[ally.ID] > 0 {
+			e.addThreat(healer.ID, threat)
+		}
+	}
+}
+
+// supportReady checks cooldown, mana and target for a support ability and
+// returns the ally it lands on (nil for area heals). Caller must hold the lock.
+func (w *World) supportReady(caster *Entity, def *SupportAbility, targetID string) (*Entity, bool) {
+	if caster.State == "DEAD" || def.Class != caster.SubType {
+		return nil, false
+	}
+	if w.now().Before(caster.SupportReady[def.ID]) {
+		return nil, false
+	}
+	if caster.Mana < caster.abilityCost(def.ManaCost) {
+		return nil, false
+	}
+	if def.Effect == SupportAreaHeal {
+		return nil, true
+	}
+	return w.supportTarget(caster, def, targetID)
+}
+
+// PerformSupportAbility casts a friendly-target ability on an ally (or self).
+// Abilities with a cast time start winding up and land when it completes.
+func (w *World) PerformSupportAbility(playerID, abilityID, targetID string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	caster, ok := w.Entities[playerID]
+	if !ok {
+		return false
+	}
+	def := GetSupportAbility(abilityID)
+	if def == nil || caster.Casting != nil || caster.isStunned(w.now()) {
+		return false
+	}
+	if _, ok := w.supportReady(caster, def, targetID); !ok {
+		return false
+	}
+	if w.startCast(caster, Cast{Ability: def.ID, TargetID: targetID, Support: true}) {
+		return true
+	}
+	return w.releaseSupportAbility(caster, def, targetID)
+}
+
+// releaseSupportAbility applies a support ability, re-checking the ally since
+// they may have moved or died during the cast. Caller must hold the lock.
+func (w *World) releaseSupportAbility(caster *Entity, def *SupportAbility, targetID string) bool {
+	target, ok := w.supportReady(caster, def, targetID)
+	if !ok {
+		return false
+	}
+	cost := caster.abilityCost(def.ManaCost)
+
+	switch def.Effect {
+	case SupportHeal:
+		w.healAlly(caster, target, caster.supportAmount(def), def.Name)
+
+	case SupportAreaHeal:
+		amount := caster.supportAmount(def)
+		for _, e := range w.Entities {
+			if e.Type != TypePlayer || e.State == "DEAD" {
+				continue
+			}
+			dx := e.X - caster.X
+			dz := e.Z - caster.Z
+			if math.Sqrt(dx*dx+dz*dz) <= def.Radius {
+				w.healAlly(caster, e, amount, def.Name)
+			}
+		}
+
+	case SupportShield:
+		// Shields do not stack; a stronger one replaces a weaker one
+		if amount := caster.supportAmount(def); amount > target.Absorb {
+			target.Absorb = amount
+		}
+		w.queueEvent(CombatEvent{Type: EventShield, SourceID: caster.ID, TargetID: target.ID, Amount: target.Absorb, Source: def.Name, X: target.X, Z: target.Z})
+
+	case SupportResurrect:
+		target.State = "IDLE"
+		target.Health = 0
+		w.heal(caster, target, int(float64(target.MaxHealth)*ResurrectHealth), def.Name)
+		if target.Health < 1 {
+			target.Health = 1
+		}
+	}
+
+	cooldown := def.Cooldown
+	if caster.CooldownReduction > 0 {
+		cooldown = time.Duration(float64(cooldown) * (1.0 - caster.CooldownReduction))
+	}
+	if caster.SupportReady == nil {
+		caster.SupportReady = make(map[string]time.Time)
+	}
+	caster.SupportReady[def.ID] = w.now().Add(cooldown)
+	caster.Mana -= cost
+	caster.State = "ATTACKING"
+	w.triggerProcs(caster, nil, ProcOnAbility, def.ID)
+	return true
+}
+
+// awardAssists gives other players on a dead enemy's threat table (healers
+// included) a share of the kill XP. Caller must hold the lock.
+func (w *World) awardAssists(target, killer *Entity, threat map[string]int, xp int) {
+	share := xp * AssistXPPercent / 100
+	if share <= 0 {
+		return
+	}
+	for id := range threat {
+		if id == killer.ID {
+			continue
+		}
+		p, ok := w.Entities[id]
+		if !ok || p.Type != TypePlayer || p.State == "DEAD" {
+			continue
+		}
+		dx := p.X - target.X
+		dz := p.Z - target.Z
+		if math.Sqrt(dx*dx+dz*dz) <= AssistRange {
+			w.awardExperience(p, share, "assist")
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package game
+
+import (
+	"testing"
+	"time"
+)
+
+// finishCast completes a wind-up as if its cast time had passed.
+func finishCast(w *World, caster *Entity) {
+	if caster.Casting == nil {
+		return
+	}
+	end := caster.Casting.End
+	w.Clock = func() time.Time { return end }
+	w.updateCast(caster)
+	w.Clock = nil
+}
+
+func newSupportTestWorld() (*World, *Entity, *Entity) {
+	w := NewWorld()
+	w.Entities = map[string]*Entity{}
+	cleric := &Entity{ID: "cleric", Type: TypePlayer, SubType: "Cleric", Level: 1, MaxExperience: 1000, X: 100, Z: 0,
+		BaseStats: Stats{Wisdom: 10, Intelligence: 20, Vitality: 10}}
+	cleric.RecalculateStats()
+	cleric.Health, cleric.Mana = cleric.MaxHealth, cleric.MaxMana
+	ally := &Entity{ID: "ally", Type: TypePlayer, SubType: "Fighter", Level: 1, MaxExperience: 1000, X: 110, Z: 0,
+		BaseStats: Stats{Vitality: 20}}
+	ally.RecalculateStats()
+	ally.Health = 10
+	w.Entities[cleric.ID] = cleric
+	w.Entities[ally.ID] = ally
+	return w, cleric, ally
+}
+
+func TestSupportHealValidatesTargetAndRange(t *testing.T) {
+	w, cleric, ally := newSupportTestWorld()
+	enemy := &Entity{ID: "Imp-1", Type: TypeEnemy, SubType: "Imp", Level: 1, Health: 50, MaxHealth: 50, X: 105}
+	enemy.addThreat(ally.ID, 20)
+	w.Entities[enemy.ID] = enemy
+
+	if w.PerformSupportAbility(cleric.ID, "heal", enemy.ID) {
+		t.Fatal("Healed an enemy")
+	}
+	if !w.PerformSupportAbility(cleric.ID, "heal", ally.ID) {
+		t.Fatal("Heal failed")
+	}
+	finishCast(w, cleric)
+	want := 10 + 30 + cleric.Stats.Wisdom*3
+	if ally.Health != want {
+		t.Errorf("Ally health = %d, want %d", ally.Health, want)
+	}
+	if enemy.Threat[cleric.ID] == 0 {
+		t.Error("Healing did not generate threat")
+	}
+	if w.PerformSupportAbility(cleric.ID, "heal", ally.ID) {
+		t.Error("Heal ignored its cooldown")
+	}
+
+	cleric.SupportReady = nil
+	ally.X = 200
+	if w.PerformSupportAbility(cleric.ID, "heal", ally.ID) {
+		t.Error("Healed an ally out of range")
+	}
+
+	ally.X = 110
+	ally.Mana = ally.MaxMana
+	if w.PerformSupportAbility(ally.ID, "heal", "") {
+		t.Error("Non-cleric cast a cleric ability")
+	}
+}
+
+func TestAreaHealShieldAndResurrect(t *testing.T) {
+	w, cleric, ally := newSupportTestWorld()
+	cleric.Health = 1
+
+	if !w.PerformSupportAbility(cleric.ID, "mass_heal", "") {
+		t.Fatal("Area heal failed")
+	}
+	finishCast(w, cleric)
+	if cleric.Health <= 1 || ally.Health <= 10 {
+		t.Errorf("Area heal missed someone: cleric %d ally %d", cleric.Health, ally.Health)
+	}
+
+	if !w.PerformSupportAbility(cleric.ID, "shield", ally.ID) || ally.Absorb == 0 {
+		t.Fatal("Shield failed")
+	}
+	before := ally.Health
+	w.ApplyDamage(nil, ally, DamageSpec{Amount: ally.Absorb})
+	if ally.Health != before || ally.Absorb != 0 {
+		t.Errorf("Shield did not absorb: health %d->%d absorb %d", before, ally.Health, ally.Absorb)
+	}
+
+	if w.PerformSupportAbility(cleric.ID, "resurrect", ally.ID) {
+		t.Fatal("Resurrected a living player")
+	}
+	w.ApplyDamage(nil, ally, DamageSpec{Amount: 10000})
+	if ally.State != "DEAD" {
+		t.Fatal("Ally did not die")
+	}
+	if !w.PerformSupportAbility(cleric.ID, "resurrect", ally.ID) {
+		t.Fatal("Resurrect failed")
+	}
+	finishCast(w, cleric)
+	if ally.State == "DEAD" || ally.Health != int(float64(ally.MaxHealth)*ResurrectHealth) {
+		t.Errorf("Resurrected ally: state %s health %d", ally.State, ally.Health)
+	}
+}
+
+func TestHealerEarnsAssistXP(t *testing.T) {
+	w, cleric, ally := newSupportTestWorld()
+	enemy := &Entity{ID: "Imp-1", Type: TypeEnemy, SubType: "Imp", Level: 5, Health: 1, MaxHealth: 50, X: 105}
+	enemy.addThreat(ally.ID, 20)
+	w.Entities[enemy.ID] = enemy
+
+	if !w.PerformSupportAbility(cleric.ID, "heal", ally.ID) {
+		t.Fatal("Heal failed")
+	}
+	finishCast(w, cleric)
+	w.ApplyDamage(ally, enemy, DamageSpec{Amount: 100, Source: "Dagger"})
+
+	xp := enemy.Level*10 + 10
+	if ally.Experience != xp {
+		t.Errorf("Killer XP = %d, want %d", ally.Experience, xp)
+	}
+	if cleric.Experience != xp*AssistXPPercent/100 {
+		t.Errorf("Healer XP = %d, want %d", cleric.Experience, xp*AssistXPPercent/100)
+	}
+}
+
+func TestSupportCooldownFollowsWorldClock(t *testing.T) {
+	w, cleric, ally := newSupportTestWorld()
+	clock := &fakeClock{t: time.Now()}
+	w.Clock = clock.Now
+
+	if !w.PerformSupportAbility(cleric.ID, "heal", ally.ID) {
+		t.Fatal("Heal failed")
+	}
+	tick(w, clock, 21) // Past the 1s cast
+	if cleric.Casting != nil || ally.Health == 10 {
+		t.Fatal("Heal should have landed")
+	}
+	if w.PerformSupportAbility(cleric.ID, "heal", ally.ID) {
+		t.Error("Heal ignored its cooldown")
+	}
+	clock.Advance(GetSupportAbility("heal").Cooldown)
+	if !w.PerformSupportAbility(cleric.ID, "heal", ally.ID) {
+		t.Error("Heal should be ready once the world clock passes its cooldown")
+	}
+}
@@ -0,0 +1,249 @@
+package game
+
+import (
+	"math"
+)
+
+const (
+	StatPointsPerLevel   = 3
+	TalentPointsPerLevel = 1
+	RespecCostPerLevel   = 100
+)
+
+// Talent-only stat keys (summed with item stats in RecalculateStats)
+const (
+	StatAbilityDamage   = "abilityDamage"   // % bonus to ability damage
+	StatAbilityCost     = "abilityCost"     // % less mana per ability
+	StatAbilityCooldown = "abilityCooldown" // % cooldown reduction
+)
+
+// ClassGrowth is the automatic stat gain per level for each class
+var ClassGrowth = map[string]Stats{
+	"Fighter": {Strength: 2, Vitality: 2, Dexterity: 1},
+	"Wizard":  {Intelligence: 2, Wisdom: 1, Vitality: 1},
+	"Rogue":   {Dexterity: 2, Strength: 1, Vitality: 1},
+	"Cleric":  {Wisdom: 2, Intelligence: 1, Vitality: 1},
+}
+
+// Growth for classes without an entry (matches the old flat gains)
+var DefaultGrowth = Stats{Strength: 2, Vitality: 2, Dexterity: 1, Intelligence: 1, Wisdom: 1}
+
+type TalentDef struct {
+	ID       string
+	Name     string
+	Class    string
+	MaxRank  int
+	MinLevel int
+	Requires string         // Talent that must be at max rank first
+	PerRank  map[string]int // Stat bonuses per rank
+	Text     string
+}
+
+var Talents = []TalentDef{
+	// Fighter
+	{ID: "toughness", Name: "Toughness", Class: "Fighter", MaxRank: 5, MinLevel: 1, PerRank: map[string]int{"vitality": 3}, Text: "+3 Vitality per rank"},
+	{ID: "iron_skin", Name: "Iron Skin", Class: "Fighter", MaxRank: 5, MinLevel: 5, Requires: "toughness", PerRank: map[string]int{StatDefense: 4}, Text: "+4 Defense per rank"},
+	{ID: "relentless", Name: "Relentless", Class: "Fighter", MaxRank: 3, MinLevel: 10, Requires: "iron_skin", PerRank: map[string]int{StatAbilityCooldown: 10}, Text: "Charge recovers 10% faster per rank"},
+	{ID: "brute_force", Name: "Brute Force", Class: "Fighter", MaxRank: 5, MinLevel: 15, Requires: "relentless", PerRank: map[string]int{"strength": 4, StatLifeOnHit: 2}, Text: "+4 Strength and +2 Life on Hit per rank"},
+
+	// Wizard
+	{ID: "arcane_mind", Name: "Arcane Mind", Class: "Wizard", MaxRank: 5, MinLevel: 1, PerRank: map[string]int{"intelligence": 3}, Text: "+3 Intelligence per rank"},
+	{ID: "searing_fire", Name: "Searing Fire", Class: "Wizard", MaxRank: 5, MinLevel: 5, Requires: "arcane_mind", PerRank: map[string]int{StatAbilityDamage: 8}, Text: "Fireball deals 8% more damage per rank"},
+	{ID: "efficiency", Name: "Efficiency", Class: "Wizard", MaxRank: 3, MinLevel: 10, Requires: "searing_fire", PerRank: map[string]int{StatAbilityCost: 10}, Text: "Fireball costs 10% less mana per rank"},
+	{ID: "ember_ward", Name: "Ember Ward", Class: "Wizard", MaxRank: 5, MinLevel: 15, Requires: "efficiency", PerRank: map[string]int{StatFireResist: 5, StatCritChance: 1}, Text: "+5% Fire Resistance and +1% Critical Strike Chance per rank"},
+
+	// Rogue
+	{ID: "agility", Name: "Agility", Class: "Rogue", MaxRank: 5, MinLevel: 1, PerRank: map[string]int{"dexterity": 3}, Text: "+3 Dexterity per rank"},
+	{ID: "keen_edge", Name: "Keen Edge", Class: "Rogue", MaxRank: 5, MinLevel: 5, Requires: "agility", PerRank: map[string]int{StatCritChance: 2}, Text: "+2% Critical Strike Chance per rank"},
+	{ID: "sharpened_blades", Name: "Sharpened Blades", Class: "Rogue", MaxRank: 5, MinLevel: 10, Requires: "keen_edge", PerRank: map[string]int{StatAbilityDamage: 8}, Text: "Thrown daggers deal 8% more damage per rank"},
+	{ID: "quick_hands", Name: "Quick Hands", Class: "Rogue", MaxRank: 3, MinLevel: 15, Requires: "sharpened_blades", PerRank: map[string]int{StatAttackSpeed: 5, StatMoveSpeed: 3}, Text: "+5% Attack Speed and +3% Movement Speed per rank"},
+
+	// Cleric
+	{ID: "devotion", Name: "Devotion", Class: "Cleric", MaxRank: 5, MinLevel: 1, PerRank: map[string]int{"wisdom": 3}, Text: "+3 Wisdom per rank"},
+	{ID: "blessed_spirits", Name: "Blessed Spirits", Class: "Cleric", MaxRank: 5, MinLevel: 5, Requires: "devotion", PerRank: map[string]int{StatAbilityDamage: 10}, Text: "Guardian Spirits deal 10% more damage per rank"},
+	{ID: "serenity", Name: "Serenity", Class: "Cleric", MaxRank: 3, MinLevel: 10, Requires: "blessed_spirits", PerRank: map[string]int{StatAbilityCost: 10, StatAbilityCooldown: 5}, Text: "Abilities cost 10% less mana and recover 5% faster per rank"},
+	{ID: "sanctuary", Name: "Sanctuary", Class: "Cleric", MaxRank: 5, MinLevel: 15, Requires: "serenity", PerRank: map[string]int{"vitality": 3, StatWaterResist: 5}, Text: "+3 Vitality and +5% Water Resistance per rank"},
+}
+
+var talentByID = func() map[string]*TalentDef {
+	m := make(map[string]*TalentDef, len(Talents))
+	for i := range Talents {
+		m[Talents[i].ID] = &Talents[i]
+	}
+	return m
+}()
+
+func GetTalent(id string) *TalentDef {
+	return talentByID[id]
+}
+
+// talentStats sums the stat bonuses from learned talents into totals.
+func (e *Entity) talentStats(totals map[string]int) {
+	for id, rank := range e.Talents {
+		def := GetTalent(id)
+		if def == nil || def.Class != e.SubType {
+			continue
+		}
+		for k, v := range def.PerRank {
+			totals[k] += v * rank
+		}
+	}
+}
+
+// applyLevelGrowth grants class growth and unspent points for one level.
+func (e *Entity) applyLevelGrowth() {
+	growth, ok := ClassGrowth[e.SubType]
+	if !ok {
+		growth = DefaultGrowth
+	}
+	e.BaseStats.Strength += growth.Strength
+	e.BaseStats.Dexterity += growth.Dexterity
+	e.BaseStats.Intelligence += growth.Intelligence
+	e.BaseStats.Wisdom += growth.Wisdom
+	e.BaseStats.Vitality += growth.Vitality
+
+	e.StatPoints += StatPointsPerLevel
+	e.TalentPoints += TalentPointsPerLevel
+}
+
+// BackfillPoints grants the per-level stat and talent points to characters
+// that levelled before points existed. Anyone with points, allocations or
+// talents has already been through the system and is left alone.
+func (e *Entity) BackfillPoints() {
+	if e.Level <= 1 || e.StatPoints != 0 || e.TalentPoints != 0 || e.AllocatedStats != (Stats{}) || len(e.Talents) > 0 {
+		return
+	}
+	e.StatPoints = (e.Level - 1) * StatPointsPerLevel
+	e.TalentPoints = (e.Level - 1) * TalentPointsPerLevel
+}
+
+// abilityCost applies talent mana cost reduction.
+func (e *Entity) abilityCost(base int) int {
+	return int(math.Ceil(float64(base) * (1.0 - e.AbilityCostReduction)))
+}
+
+// abilityDamage applies talent ability damage bonuses.
+func (e *Entity) abilityDamage(base int) int {
+	return int(float64(base) * (1.0 + e.AbilityDamageBonus))
+}
+
+// PerformAllocateStats spends unspent attribute points.
+func (w *World) PerformAllocateStats(playerID string, points Stats) (*Entity, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	player, ok := w.Entities[playerID]
+	if !ok {
+		return nil, false
+	}
+	// Check each value and the running total so huge values cannot wrap the sum
+	total := 0
+	for _, n := range []int{points.Strength, points.Dexterity, points.Intelligence, points.Wisdom, points.Vitality} {
+		if n < 0 || n > player.StatPoints {
+			return nil, false
+		}
+		total += n
+		if total > player.StatPoints {
+			return nil, false
+		}
+	}
+	if total == 0 {
+		return nil, false
+	}
+
+	player.StatPoints -= total
+	player.BaseStats.Strength += points.Strength
+	player.BaseStats.Dexterity += points.Dexterity
+	player.BaseStats.Intelligence += points.Intelligence
+	player.BaseStats.Wisdom += points.Wisdom
+	player.BaseStats.Vitality += points.Vitality
+
+	player.AllocatedStats.Strength += points.Strength
+	player.AllocatedStats.Dexterity += points.Dexterity
+	player.AllocatedStats.Intelligence += points.Intelligence
+	player.AllocatedStats.Wisdom += points.Wisdom
+	player.AllocatedStats.Vitality += points.Vitality
+
+	player.RecalculateStats()
+	return player, true
+}
+
+// PerformLearnTalent spends a talent point on one rank of a class talent.
+func (w *World) PerformLearnTalent(playerID, talentID string) (*Entity, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	player, ok := w.Entities[playerID]
+	if !ok || player.TalentPoints <= 0 {
+		return nil, false
+	}
+	def := GetTalent(talentID)
+	if def == nil || def.Class != player.SubType || player.Level < def.MinLevel {
+		return nil, false
+	}
+	if player.Talents[talentID] >= def.MaxRank {
+		return nil, false
+	}
+	if def.Requires != "" {
+		req := GetTalent(def.Requires)
+		if req == nil || player.Talents[req.ID] < req.MaxRank {
+			return nil, false
+		}
+	}
+
+	if player.Talents == nil {
+		player.Talents = make(map[string]int)
+	}
+	player.Talents[talentID]++
+	player.TalentPoints--
+	player.RecalculateStats()
+	return player, true
+}
+
+// RespecCost is the gold to refund all attribute and talent points.
+func RespecCost(level int) int {
+	return level * RespecCostPerLevel
+}
+
+// PerformRespec refunds allocated attribute points and talents for gold.
+func (w *World) PerformRespec(playerID string) (*Entity, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	player, ok := w.Entities[playerID]
+	if !ok || player.State == "DEAD" || !InSafeZone(player.X, player.Z) {
+		return nil, false
+	}
+	cost := RespecCost(player.Level)
+	if player.Gold < cost {
+		return nil, false
+	}
+
+	a := player.AllocatedStats
+	refund := a.Strength + a.Dexterity + a.Intelligence + a.Wisdom + a.Vitality
+	talents := 0
+	for _, rank := range player.Talents {
+		talents += rank
+	}
+	if refund == 0 && talents == 0 {
+		return nil, false
+	}
+
+	player.Gold -= cost
+	player.BaseStats.Strength -= a.Strength
+	player.BaseStats.Dexterity -= a.Dexterity
+	player.BaseStats.Intelligence -= a.Intelligence
+	player.BaseStats.Wisdom -= a.Wisdom
+	player.BaseStats.Vitality -= a.Vitality
+	player.AllocatedStats = Stats{}
+	player.StatPoints += refund
+
+	player.Talents = nil
+	player.TalentPoints += talents
+
+	player.RecalculateStats()
+	if player.Health > player.MaxHealth {
+		player.Health = player.MaxHealth
+	}
+	return player, true
+}
@@ -0,0 +1,118 @@
+package game
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLevelGrowthByClass(t *testing.T) {
+	wizard := &Entity{ID: "p1", Type: TypePlayer, SubType: "Wizard", BaseStats: Stats{Intelligence: 10}}
+	wizard.applyLevelGrowth()
+
+	if wizard.BaseStats.Intelligence != 10+ClassGrowth["Wizard"].Intelligence {
+		t.Errorf("Intelligence = %d", wizard.BaseStats.Intelligence)
+	}
+	if wizard.StatPoints != StatPointsPerLevel || wizard.TalentPoints != TalentPointsPerLevel {
+		t.Errorf("Points = %d/%d", wizard.StatPoints, wizard.TalentPoints)
+	}
+}
+
+func TestAllocateLearnAndRespec(t *testing.T) {
+	w := NewWorld()
+	player := &Entity{
+		ID:           "p1",
+		Type:         TypePlayer,
+		SubType:      "Wizard",
+		Level:        10,
+		BaseStats:    Stats{Intelligence: 10, Vitality: 10},
+		StatPoints:   3,
+		TalentPoints: 6,
+		Gold:         RespecCost(10),
+	}
+	player.RecalculateStats()
+	w.Entities[player.ID] = player
+
+	if _, ok := w.PerformAllocateStats(player.ID, Stats{Intelligence: 4}); ok {
+		t.Fatal("Allocated more points than available")
+	}
+	if _, ok := w.PerformAllocateStats(player.ID, Stats{Intelligence: 3}); !ok {
+		t.Fatal("Allocation failed")
+	}
+	if player.BaseStats.Intelligence != 13 || player.StatPoints != 0 {
+		t.Fatalf("After allocation: int %d, points %d", player.BaseStats.Intelligence, player.StatPoints)
+	}
+
+	// Prerequisite must be maxed first
+	if _, ok := w.PerformLearnTalent(player.ID, "searing_fire"); ok {
+		t.Fatal("Learned a talent without its prerequisite")
+	}
+	if _, ok := w.PerformLearnTalent(player.ID, "agility"); ok {
+		t.Fatal("Learned another class's talent")
+	}
+	for i := 0; i < 5; i++ {
+		if _, ok := w.PerformLearnTalent(player.ID, "arcane_mind"); !ok {
+			t.Fatalf("Learning arcane_mind rank %d failed", i+1)
+		}
+	}
+	if _, ok := w.PerformLearnTalent(player.ID, "searing_fire"); !ok {
+		t.Fatal("Learning searing_fire failed")
+	}
+	if player.Stats.Intelligence != 13+15 {
+		t.Errorf("Intelligence = %d, want 28", player.Stats.Intelligence)
+	}
+	if player.AbilityDamageBonus != 0.08 {
+		t.Errorf("AbilityDamageBonus = %v, want 0.08", player.AbilityDamageBonus)
+	}
+
+	if _, ok := w.PerformRespec(player.ID); !ok {
+		t.Fatal("Respec failed")
+	}
+	if player.BaseStats.Intelligence != 10 || player.StatPoints != 3 || player.TalentPoints != 6 || len(player.Talents) != 0 {
+		t.Errorf("After respec: int %d, stat points %d, talent points %d, talents %v",
+			player.BaseStats.Intelligence, player.StatPoints, player.TalentPoints, player.Talents)
+	}
+	if player.Gold != 0 {
+		t.Errorf("Gold = %d after respec, want 0", player.Gold)
+	}
+}
+
+func TestAllocateStatsRejectsOverflow(t *testing.T) {
+	w := NewWorld()
+	player := &Entity{ID: "p1", Type: TypePlayer, SubType: "Fighter", Level: 5,
+		BaseStats: Stats{Strength: 10, Vitality: 10}, StatPoints: 3}
+	player.RecalculateStats()
+	w.Entities[player.ID] = player
+
+	for _, points := range []Stats{
+		{Strength: math.MaxInt64, Dexterity: 1},
+		{Strength: 1 << 62, Dexterity: 1 << 62, Intelligence: 1 << 62, Wisdom: 1 << 62, Vitality: 1}, // Sum wraps to 1
+	} {
+		if _, ok := w.PerformAllocateStats(player.ID, points); ok {
+			t.Errorf("Allocated %+v", points)
+		}
+	}
+	if player.StatPoints != 3 || player.BaseStats.Strength != 10 {
+		t.Errorf("Rejected allocations changed points %d / strength %d", player.StatPoints, player.BaseStats.Strength)
+	}
+}
+
+func TestBackfillPointsForOldCharacters(t *testing.T) {
+	old := &Entity{Level: 8}
+	old.BackfillPoints()
+	if old.StatPoints != 7*StatPointsPerLevel || old.TalentPoints != 7*TalentPointsPerLevel {
+		t.Errorf("Backfilled %d/%d points", old.StatPoints, old.TalentPoints)
+	}
+
+	for _, e := range []*Entity{
+		{Level: 1},
+		{Level: 8, StatPoints: 2},
+		{Level: 8, AllocatedStats: Stats{Strength: 21}},
+		{Level: 8, Talents: map[string]int{"toughness": 1}},
+	} {
+		before := *e
+		e.BackfillPoints()
+		if e.StatPoints != before.StatPoints || e.TalentPoints != before.TalentPoints {
+			t.Errorf("Backfilled a character that needs none: %+v", before)
+		}
+	}
+}
@@ -0,0 +1,337 @@
+package game
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+)
+
+// Max distance between two players for a trade to start or stay open
+const TradeRange = 10.0
+
+type TradeOffer struct {
+	Items     []Item `json:"items"`
+	Gold      int    `json:"gold"`
+	Locked    bool   `json:"locked"`
+	Confirmed bool   `json:"confirmed"`
+}
+
+// Trade is a two-phase player-to-player exchange. Both sides place items and
+// gold, lock their offer, then confirm. Changing an offer unlocks both sides.
+type Trade struct {
+	ID      string                 `json:"id"`
+	PlayerA string                 `json:"playerA"`
+	PlayerB string                 `json:"playerB"`
+	Offers  map[string]*TradeOffer `json:"offers"`
+}
+
+// TradeCancel is sent through OnEvent when the server closes a trade on its own.
+type TradeCancel struct {
+	Trade  *Trade
+	Reason string
+}
+
+func (t *Trade) other(playerID string) string {
+	if t.PlayerA == playerID {
+		return t.PlayerB
+	}
+	return t.PlayerA
+}
+
+func (t *Trade) unlock() {
+	for _, o := range t.Offers {
+		o.Locked = false
+		o.Confirmed = false
+	}
+}
+
+func (t *Trade) Copy() *Trade {
+	c := &Trade{
+		ID:      t.ID,
+		PlayerA: t.PlayerA,
+		PlayerB: t.PlayerB,
+		Offers:  make(map[string]*TradeOffer, len(t.Offers)),
+	}
+	for id, o := range t.Offers {
+		offer := *o
+		offer.Items = make([]Item, len(o.Items))
+		copy(offer.Items, o.Items)
+		c.Offers[id] = &offer
+	}
+	return c
+}
+
+func tradeDistance(a, b *Entity) float64 {
+	dx := a.X - b.X
+	dz := a.Z - b.Z
+	return math.Sqrt(dx*dx + dz*dz)
+}
+
+// RequestTrade records a pending trade request from one player to another.
+func (w *World) RequestTrade(fromID, toID string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if fromID == toID {
+		return false
+	}
+	from, ok := w.Entities[fromID]
+	if !ok || from.Type != TypePlayer || from.State == "DEAD" {
+		return false
+	}
+	to, ok := w.Entities[toID]
+	if !ok || to.Type != TypePlayer || to.State == "DEAD" {
+		return false
+	}
+	if w.Trades[fromID] != nil || w.Trades[toID] != nil {
+		return false
+	}
+	if tradeDistance(from, to) > TradeRange {
+		return false
+	}
+
+	w.TradeRequests[toID] = fromID
+	return true
+}
+
+// AcceptTrade opens a trade window if requesterID has a pending request to playerID.
+func (w *World) AcceptTrade(playerID, requesterID string) (*Trade, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.TradeRequests[playerID] != requesterID {
+		return nil, false
+	}
+	delete(w.TradeRequests, playerID)
+
+	player, ok := w.Entities[playerID]
+	if !ok || player.State == "DEAD" {
+		return nil, false
+	}
+	requester, ok := w.Entities[requesterID]
+	if !ok || requester.State == "DEAD" {
+		return nil, false
+	}
+	if w.Trades[playerID] != nil || w.Trades[requesterID] != nil {
+		return nil, false
+	}
+	if tradeDistance(player, requester) > TradeRange {
+		return nil, false
+	}
+
+	trade := &Trade{
+		ID:      fmt.Sprintf("trade-%d", time.Now().UnixNano()),
+		PlayerA: requesterID,
+		PlayerB: playerID,
+		Offers: map[string]*TradeOffer{
+			requesterID: {Items: make([]Item, 0)},
+			playerID:    {Items: make([]Item, 0)},
+		},
+	}
+	w.Trades[requesterID] = trade
+	w.Trades[playerID] = trade
+	return trade.Copy(), true
+}
+
+// SetTradeOffer replaces the player's side of the trade window.
+func (w *World) SetTradeOffer(playerID string, itemIDs []string, gold int) (*Trade, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	trade := w.Trades[playerID]
+	if trade == nil {
+		return nil, false
+	}
+	player, ok := w.Entities[playerID]
+	if !ok {
+		return nil, false
+	}
+	if gold < 0 || gold > player.Gold {
+		return nil, false
+	}
+
+	items := make([]Item, 0, len(itemIDs))
+	seen := make(map[string]bool)
+	for _, id := range itemIDs {
+		if seen[id] {
+			return nil, false
+		}
+		seen[id] = true
+
+		idx := player.findInventoryItem(id)
+		if idx == -1 {
+			return nil, false
+		}
+		items = append(items, player.Inventory[idx].clone()) // Snapshot of what the partner sees
+	}
+
+	offer := trade.Offers[playerID]
+	offer.Items = items
+	offer.Gold = gold
+	trade.unlock()
+	return trade.Copy(), true
+}
+
+func (w *World) LockTrade(playerID string) (*Trade, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	trade := w.Trades[playerID]
+	if trade == nil {
+		return nil, false
+	}
+	trade.Offers[playerID].Locked = true
+	return trade.Copy(), true
+}
+
+// ConfirmTrade confirms the player's side. Once both sides are locked and
+// confirmed the exchange is executed atomically; completed is true in that case.
+func (w *World) ConfirmTrade(playerID string) (trade *Trade, completed bool, ok bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	t := w.Trades[playerID]
+	if t == nil {
+		return nil, false, false
+	}
+	for _, o := range t.Offers {
+		if !o.Locked {
+			return nil, false, false
+		}
+	}
+	t.Offers[playerID].Confirmed = true
+
+	for _, o := range t.Offers {
+		if !o.Confirmed {
+			return t.Copy(), false, true
+		}
+	}
+
+	// Both confirmed - swap
+	if !w.executeTrade(t) {
+		w.closeTrade(t)
+		return t.Copy(), false, false
+	}
+	w.closeTrade(t)
+	return t.Copy(), true, true
+}
+
+// executeTrade re-validates both offers against current inventories and swaps them.
+// Any offered item that changed since it was offered aborts the trade.
+// Caller must hold the lock.
+func (w *World) executeTrade(t *Trade) bool {
+	a, okA := w.Entities[t.PlayerA]
+	b, okB := w.Entities[t.PlayerB]
+	if !okA || !okB || a.State == "DEAD" || b.State == "DEAD" {
+		return false
+	}
+	offerA := t.Offers[t.PlayerA]
+	offerB := t.Offers[t.PlayerB]
+
+	// Validate ownership and funds
+	for _, pair := range []struct {
+		p     *Entity
+		offer *TradeOffer
+	}{{a, offerA}, {b, offerB}} {
+		if pair.p.Gold < pair.offer.Gold {
+			return false
+		}
+		// Items must be exactly as offered; gems, enchants and wear change them in place
+		for _, item := range pair.offer.Items {
+			idx := pair.p.findInventoryItem(item.ID)
+			if idx == -1 || !reflect.DeepEqual(pair.p.Inventory[idx], item) {
+				return false
+			}
+		}
+	}
+
+	// Validate space after the swap
+	if len(a.Inventory)-len(offerA.Items)+len(offerB.Items) > MaxInventorySize {
+		return false
+	}
+	if len(b.Inventory)-len(offerB.Items)+len(offerA.Items) > MaxInventorySize {
+		return false
+	}
+
+	// Take items out first so both inventories have room
+	var fromA, fromB []Item
+	for _, item := range offerA.Items {
+		taken, _ := a.removeInventoryItem(item.ID)
+		fromA = append(fromA, taken)
+	}
+	for _, item := range offerB.Items {
+		taken, _ := b.removeInventoryItem(item.ID)
+		fromB = append(fromB, taken)
+	}
+	a.Inventory = append(a.Inventory, fromB...)
+	b.Inventory = append(b.Inventory, fromA...)
+
+	a.Gold += offerB.Gold - offerA.Gold
+	b.Gold += offerA.Gold - offerB.Gold
+	return true
+}
+
+// CancelTrade closes the player's open trade (or declines a pending request).
+func (w *World) CancelTrade(playerID string) (*Trade, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	delete(w.TradeRequests, playerID)
+
+	trade := w.Trades[playerID]
+	if trade == nil {
+		return nil, false
+	}
+	w.closeTrade(trade)
+	return trade.Copy(), true
+}
+
+// closeTrade removes a trade from both participants. Caller must hold the lock.
+func (w *World) closeTrade(t *Trade) {
+	delete(w.Trades, t.PlayerA)
+	delete(w.Trades, t.PlayerB)
+}
+
+// cancelTradeFor closes any trade involving playerID and notifies listeners.
+// Caller must hold the lock.
+func (w *World) cancelTradeFor(playerID, reason string) {
+	delete(w.TradeRequests, playerID)
+	for to, from := range w.TradeRequests {
+		if from == playerID {
+			delete(w.TradeRequests, to)
+		}
+	}
+
+	trade := w.Trades[playerID]
+	if trade == nil {
+		return
+	}
+	w.closeTrade(trade)
+	if w.OnEvent != nil {
+		w.OnEvent("trade_cancel", TradeCancel{Trade: trade.Copy(), Reason: reason})
+	}
+}
+
+// updateTrades cancels trades whose participants died or walked apart.
+// Caller must hold the lock.
+func (w *World) updateTrades() {
+	for playerID, trade := range w.Trades {
+		if playerID != trade.PlayerA {
+			continue // Each trade is stored under both players
+		}
+		a, okA := w.Entities[trade.PlayerA]
+		b, okB := w.Entities[trade.PlayerB]
+		if !okA || !okB {
+			w.cancelTradeFor(playerID, "disconnected")
+			continue
+		}
+		if a.State == "DEAD" || b.State == "DEAD" {
+			w.cancelTradeFor(playerID, "died")
+			continue
+		}
+		if tradeDistance(a, b) > TradeRange {
+			w.cancelTradeFor(playerID, "too far away")
+		}
+	}
+}
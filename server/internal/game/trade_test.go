@@ -0,0 +1,121 @@
+package game
+
+import (
+	"testing"
+)
+
+func setupTrade(t *testing.T) (*World, *Entity, *Entity) {
+	w := NewWorld()
+	a := &Entity{ID: "player-a", Type: TypePlayer, State: "IDLE", Gold: 100,
+		Inventory: []Item{{ID: "sword", Name: "Iron Sword"}}}
+	b := &Entity{ID: "player-b", Type: TypePlayer, State: "IDLE", X: 3, Gold: 50,
+		Inventory: []Item{{ID: "helm", Name: "Iron Helm"}}}
+	w.AddEntity(a)
+	w.AddEntity(b)
+
+	if !w.RequestTrade(a.ID, b.ID) {
+		t.Fatal("RequestTrade failed")
+	}
+	if _, ok := w.AcceptTrade(b.ID, a.ID); !ok {
+		t.Fatal("AcceptTrade failed")
+	}
+	return w, a, b
+}
+
+func TestTradeSwap(t *testing.T) {
+	w, a, b := setupTrade(t)
+
+	if _, ok := w.SetTradeOffer(a.ID, []string{"sword"}, 40); !ok {
+		t.Fatal("SetTradeOffer A failed")
+	}
+	if _, ok := w.SetTradeOffer(b.ID, []string{"helm"}, 0); !ok {
+		t.Fatal("SetTradeOffer B failed")
+	}
+
+	// Confirm before locking is rejected
+	if _, _, ok := w.ConfirmTrade(a.ID); ok {
+		t.Fatal("ConfirmTrade succeeded before both sides locked")
+	}
+
+	w.LockTrade(a.ID)
+	w.LockTrade(b.ID)
+	if _, completed, ok := w.ConfirmTrade(a.ID); !ok || completed {
+		t.Fatalf("First confirm: ok=%v completed=%v", ok, completed)
+	}
+	if _, completed, ok := w.ConfirmTrade(b.ID); !ok || !completed {
+		t.Fatalf("Second confirm: ok=%v completed=%v", ok, completed)
+	}
+
+	if len(a.Inventory) != 1 || a.Inventory[0].ID != "helm" {
+		t.Errorf("Player A inventory = %+v, want helm", a.Inventory)
+	}
+	if len(b.Inventory) != 1 || b.Inventory[0].ID != "sword" {
+		t.Errorf("Player B inventory = %+v, want sword", b.Inventory)
+	}
+	if a.Gold != 60 || b.Gold != 90 {
+		t.Errorf("Gold after trade: A=%d B=%d, want 60/90", a.Gold, b.Gold)
+	}
+	if w.Trades[a.ID] != nil || w.Trades[b.ID] != nil {
+		t.Error("Trade still open after completion")
+	}
+}
+
+func TestTradeOfferChangeUnlocks(t *testing.T) {
+	w, a, b := setupTrade(t)
+
+	w.LockTrade(a.ID)
+	w.LockTrade(b.ID)
+	trade, ok := w.SetTradeOffer(b.ID, nil, 10)
+	if !ok {
+		t.Fatal("SetTradeOffer failed")
+	}
+	if trade.Offers[a.ID].Locked || trade.Offers[b.ID].Locked {
+		t.Error("Changing an offer did not unlock both sides")
+	}
+}
+
+func TestTradeCancelledByDistance(t *testing.T) {
+	w, a, b := setupTrade(t)
+
+	var cancelled bool
+	w.OnEvent = func(eventType string, data interface{}) {
+		if eventType == "trade_cancel" {
+			cancelled = true
+		}
+	}
+
+	b.X = 200
+	w.Update(0.05)
+
+	if !cancelled {
+		t.Error("No trade_cancel event when players walked apart")
+	}
+	if w.Trades[a.ID] != nil {
+		t.Error("Trade still open after players walked apart")
+	}
+}
+
+func TestTradeAbortsIfOfferedItemChanged(t *testing.T) {
+	w, a, b := setupTrade(t)
+	helm := createItem(BaseItem{"Iron Helm", ItemArmor, "head", "defense", 4, ""}, RarityRare, 3.0, 0, 5)
+	helm.Sockets = 1
+	helm.Gems = []Item{*NewGem("ruby", 2)}
+	helm.RebuildStats()
+	a.Inventory = []Item{*helm}
+
+	w.SetTradeOffer(a.ID, []string{helm.ID}, 0)
+	w.LockTrade(a.ID)
+	w.LockTrade(b.ID)
+	w.ConfirmTrade(a.ID)
+
+	// Strip the gem after the partner has seen and locked the offer
+	if _, ok := w.PerformUnsocket(a.ID, helm.ID, 0); !ok {
+		t.Fatal("Unsocket failed")
+	}
+	if _, completed, _ := w.ConfirmTrade(b.ID); completed {
+		t.Fatal("Trade completed with a changed item")
+	}
+	if len(b.Inventory) != 1 || b.Inventory[0].ID != "helm" || len(a.Inventory) != 2 {
+		t.Errorf("Inventories changed: A=%d B=%+v", len(a.Inventory), b.Inventory)
+	}
+}
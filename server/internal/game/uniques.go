@@ -0,0 +1,126 @@
+package game
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// UniqueDef is a named legendary with fixed stats and a special effect
+type UniqueDef struct {
+	ID       string
+	Name     string
+	Base     string // BaseItem name
+	MinLevel int
+	Stats    map[string]int
+	Proc     ProcDef
+	Lore     string
+}
+
+var Uniques = []UniqueDef{
+	{
+		ID: "emberheart", Name: "Emberheart", Base: "Wooden Staff", MinLevel: 5,
+		Stats: map[string]int{"intelligence": 30, StatCritChance: 5},
+		Proc: ProcDef{Trigger: ProcOnHit, Effect: EffectChainFireball, Chance: 0.5, Value: 60, Source: "Fireball",
+			Summary: "Fireball has a 50% chance to chain to a nearby enemy for 60% damage"},
+		Lore: "It still remembers the Shifting Sands before the mirage.",
+	},
+	{
+		ID: "stampede_greaves", Name: "Stampede Greaves", Base: "Plate Greaves", MinLevel: 5,
+		Stats: map[string]int{"strength": 20, "vitality": 20, StatMoveSpeed: 10},
+		Proc: ProcDef{Trigger: ProcOnAbility, Effect: EffectBurningTrail, Chance: 1, Value: 15, Source: "Charge",
+			Summary: "Charge leaves a trail of fire that burns enemies for 15 damage"},
+		Lore: "Every step a promise, every stride a blaze.",
+	},
+	{
+		ID: "gravewarden", Name: "Gravewarden's Mace", Base: "Cleric Mace", MinLevel: 5,
+		Stats: map[string]int{"wisdom": 25, StatLifeOnHit: 10},
+		Proc: ProcDef{Trigger: ProcOnKill, Effect: EffectHeal, Chance: 1, Value: 10,
+			Summary: "Killing an enemy heals you for 10% of your maximum health"},
+		Lore: "Those it lays to rest give back what they took.",
+	},
+	{
+		ID: "whisperfang", Name: "Whisperfang", Base: "Steel Dagger", MinLevel: 10,
+		Stats: map[string]int{"dexterity": 30, StatAttackSpeed: 10},
+		Proc: ProcDef{Trigger: ProcOnKill, Effect: EffectExplode, Chance: 0.25, Value: 50,
+			Summary: "Enemies you kill have a 25% chance to burst for 50 damage to nearby enemies"},
+		Lore: "A doubt, sharpened.",
+	},
+	{
+		ID: "crown_of_the_tide", Name: "Crown of the Tide", Base: "Iron Helm", MinLevel: 10,
+		Stats: map[string]int{"intelligence": 15, "wisdom": 15, StatWaterResist: 25},
+		Proc: ProcDef{Trigger: ProcOnAbility, Effect: EffectRestoreMana, Chance: 1, Value: 10,
+			Summary: "Using an ability restores 10 mana"},
+		Lore: "Lady Elara wore it before the Drowning.",
+	},
+}
+
+var uniqueByID = func() map[string]*UniqueDef {
+	m := make(map[string]*UniqueDef, len(Uniques))
+	for i := range Uniques {
+		m[Uniques[i].ID] = &Uniques[i]
+	}
+	return m
+}()
+
+func GetUnique(id string) *UniqueDef {
+	return uniqueByID[id]
+}
+
+func findBaseItem(name string) (BaseItem, bool) {
+	for _, b := range BaseItems {
+		if b.Name == name {
+			return b, true
+		}
+	}
+	return BaseItem{}, false
+}
+
+// GenerateUnique rolls a random unique legendary that can drop at this level
+// (optionally restricted to a slot). Returns nil if none qualify.
+func GenerateUnique(level int, slot string) *Item {
+	var candidates []*UniqueDef
+	for i := range Uniques {
+		if Uniques[i].MinLevel > level {
+			continue
+		}
+		if base, ok := findBaseItem(Uniques[i].Base); !ok || (slot != "" && base.Slot != slot) {
+			continue
+		}
+		candidates = append(candidates, &Uniques[i])
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	return createUnique(candidates[rand.Intn(len(candidates))], level)
+}
+
+func createUnique(def *UniqueDef, level int) *Item {
+	base, ok := findBaseItem(def.Base)
+	if !ok {
+		return nil
+	}
+	multiplier := RarityMultiplier[RarityLegendary]
+	baseVal := int(float64(base.BaseValue) * (1.0 + float64(level)*0.15) * multiplier)
+
+	item := &Item{
+		ID:       fmt.Sprintf("item-%d", rand.Int63()),
+		Name:     def.Name,
+		Type:     base.Type,
+		Rarity:   RarityLegendary,
+		Slot:     base.Slot,
+		Level:    level,
+		Value:    level * 10 * int(multiplier),
+		Base:     base.Name,
+		Implicit: map[string]int{base.BaseStat: baseVal},
+		UniqueID: def.ID,
+		Sockets:  rollSockets(RarityLegendary),
+	}
+	for k, v := range def.Stats {
+		item.Implicit[k] += v
+	}
+	item.MaxDurability = maxDurability(level)
+	item.Durability = item.MaxDurability
+	item.RebuildStats()
+	item.Description = fmt.Sprintf("%s\n%s", def.Proc.Summary, def.Lore)
+	return item
+}
@@ -27,6 +27,14 @@ type Stats struct {
 	Vitality     int `json:"vitality"`
 }
 
+// Resistances are percentages (0-100) per element
+type Resistances struct {
+	Fire  int `json:"fire"`
+	Water int `json:"water"`
+	Earth int `json:"earth"`
+	Air   int `json:"air"`
+}
+
 type Entity struct {
 	ID            string     `json:"id"`
 	Name          string     `json:"name"`
@@ -48,6 +56,9 @@ type Entity struct {
 	// Inventory
 	Inventory []Item          `json:"-"`
 	Equipment map[string]Item `json:"equipment"`
+	Stash     *Stash          `json:"-"` // Account-wide, only set for players
+	Materials map[string]int  `json:"-"` // Crafting materials from salvaging
+	Profile   *Profile        `json:"-"` // Account-wide achievements and lifetime stats
 
 	// Stats
 	BaseStats Stats `json:"baseStats"` // Naked stats
@@ -63,27 +74,74 @@ type Entity struct {
 	ManaRegen         float64 `json:"manaRegen"`
 	CastSpeed         float64 `json:"castSpeed"`
 
+	// Affix Stats
+	CritChance  float64     `json:"critChance"`  // 0-1
+	DodgeChance float64     `json:"dodgeChance"` // 0-1, from Dexterity
+	BlockChance float64     `json:"blockChance"` // 0-1, needs a shield
+	LifeOnHit   int         `json:"lifeOnHit"`
+	Resistances Resistances `json:"resistances"`
+	MagicFind   float64     `json:"magicFind"` // 0.25 = +25%
+
+	// Loot
+	LootPity int `json:"-"` // Item drops since the last Legendary
+
+	// Progression
+	StatPoints           int            `json:"statPoints"`   // Unspent attribute points
+	TalentPoints         int            `json:"talentPoints"` // Unspent talent points
+	AllocatedStats       Stats          `json:"-"`            // Points spent by the player (refunded on respec)
+	Talents              map[string]int `json:"-"`            // Talent ID -> rank
+	AbilityDamageBonus   float64        `json:"-"`
+	AbilityCostReduction float64        `json:"-"`
+
+	// Quests
+	Quests          []QuestProgress `json:"-"` // Accepted, not yet turned in
+	CompletedQuests map[string]bool `json:"-"`
+
+	// Uniques & Sets
+	Procs       []ProcDef      `json:"-"`
+	SetPieces   map[string]int `json:"setPieces,omitempty"` // Set ID -> equipped count
+	TrailDamage int            `json:"-"`                   // Burning trail while charging
+	LastTrailX  float64        `json:"-"`
+	LastTrailZ  float64        `json:"-"`
+
 	TargetX float64 `json:"-"`
 	TargetZ float64 `json:"-"`
 	SpawnX  float64 `json:"-"`
 	SpawnZ  float64 `json:"-"`
-	State   string  `json:"state"` // IDLE, MOVING, ATTACKING, DEAD
+	State   string  `json:"state"` // IDLE, MOVING, ATTACKING, CASTING, DEAD
 
 	// Combat
-	LastAttackTime  time.Time     `json:"-"`
-	AttackCooldown  time.Duration `json:"-"`
-	LastAbilityTime time.Time     `json:"-"`
-	AbilityCooldown time.Duration `json:"-"`
+	LastAttackTime  time.Time            `json:"-"`
+	AttackCooldown  time.Duration        `json:"-"`
+	LastAbilityTime time.Time            `json:"-"`
+	AbilityCooldown time.Duration        `json:"-"`
+	SupportReady    map[string]time.Time `json:"-"` // Support ability ID -> ready time
 
 	// Loot
-	LootItem *Item     `json:"lootItem,omitempty"` // If Type == TypeLoot
-	LootTime time.Time `json:"-"`
+	LootItem   *Item     `json:"lootItem,omitempty"` // If Type == TypeLoot
+	LootTime   time.Time `json:"-"`
+	LootOwner  string    `json:"lootOwner,omitempty"`  // Only this player may pick it up until LootFreeAt
+	LootFreeAt int64     `json:"lootFreeAt,omitempty"` // Unix ms when the drop becomes free-for-all
 
 	// Projectile
-	OwnerID string  `json:"ownerId,omitempty"`
-	VelX    float64 `json:"velX"`
-	VelZ    float64 `json:"velZ"`
-	Radius  float64 `json:"-"`
+	OwnerID    string     `json:"ownerId,omitempty"`
+	VelX       float64    `json:"velX"`
+	VelZ       float64    `json:"velZ"`
+	Radius     float64    `json:"-"`
+	ExpireTime time.Time  `json:"-"`                 // Zero means no lifetime (cleaned up by distance)
+	LastTick   time.Time  `json:"-"`                 // Area damage tick
+	NoProc     bool       `json:"-"`                 // Hits do not trigger item procs
+	IgnoreID   string     `json:"-"`                 // Entity this projectile cannot hit (a chain's source)
+	Element    DamageType `json:"element,omitempty"` // Damage type of projectile hits and enemy attacks
+	Hostile    bool       `json:"hostile,omitempty"` // Fired by an enemy; hits players instead of enemies
+	Splash     float64    `json:"-"`                 // Hostile bursts: radius for other players
+
+	// Combat state
+	Absorb       int            `json:"absorb,omitempty"`  // Damage shield soaked before health
+	Threat       map[string]int `json:"-"`                 // Enemies: player ID -> accumulated threat
+	Casting      *Cast          `json:"casting,omitempty"` // Ability being wound up
+	StunnedUntil time.Time      `json:"-"`
+	TeleportSeq  int            `json:"-"` // Server teleports so far; older client moves are ignored
 
 	// Abilities
 	SpiritsActive  bool      `json:"spiritsActive"`
@@ -92,6 +150,16 @@ type Entity struct {
 	IsCharging     bool      `json:"isCharging,omitempty"`
 	ChargeTargetX  float64   `json:"-"`
 	ChargeTargetZ  float64   `json:"-"`
+
+	// Enemy AI (see ai.go)
+	AIState     AIState    `json:"-"`
+	AITarget    string     `json:"-"` // Player engaged last tick
+	FleeUntil   time.Time  `json:"-"`
+	Fled        bool       `json:"-"` // Enemies flee once per life
+	ChargeReady time.Time  `json:"-"`
+	Path        []Waypoint `json:"-"` // Remaining A* waypoints
+	PathGoal    Waypoint   `json:"-"` // Where Path leads
+	PathFailed  bool       `json:"-"` // No way to PathGoal; wait for the goal to move
 }
 
 type World struct {
@@ -101,19 +169,57 @@ type World struct {
 	// Elite Spawning
 	EliteSpawnTimer time.Time
 
+	// How long a drop stays reserved for its killer
+	LootOwnershipWindow time.Duration
+
+	// Realm restoration (zone name -> progress)
+	RegionKills     map[string]int
+	RestoredRegions map[string]bool
+
 	// Global Regen Timer
 	RegenTimer float64
 
+	// Trading (both participants map to the same Trade)
+	Trades        map[string]*Trade
+	TradeRequests map[string]string // target ID -> requester ID
+
+	// Merchant
+	MerchantStock       []Item
+	MerchantRestockTime time.Time
+	Buyback             map[string][]Item // Player ID -> recently sold items
+
+	// Damage pipeline hooks (see ApplyDamage)
+	PreDamageHooks  []DamageHook
+	PostDamageHooks []DamageHook
+
+	// Combat stream drained by the hub each tick
+	events []CombatEvent
+
+	// Static terrain for movement and pathfinding
+	Collision *CollisionMap
+
+	// Time source for enemy AI; nil means time.Now (tests use a fake clock)
+	Clock func() time.Time
+
 	// Event Callback
 	OnEvent func(eventType string, data interface{})
 }
 
 func NewWorld() *World {
 	w := &World{
-		Entities:        make(map[string]*Entity),
-		EliteSpawnTimer: time.Now(),
-		RegenTimer:      0,
-		OnEvent:         func(eventType string, data interface{}) {}, // Default no-op
+		Entities:            make(map[string]*Entity),
+		EliteSpawnTimer:     time.Now(),
+		RegenTimer:          0,
+		Trades:              make(map[string]*Trade),
+		TradeRequests:       make(map[string]string),
+		Buyback:             make(map[string][]Item),
+		LootOwnershipWindow: DefaultLootOwnershipWindow,
+		RegionKills:         make(map[string]int),
+		RestoredRegions:     make(map[string]bool),
+		PreDamageHooks:      defaultPreDamageHooks(),
+		PostDamageHooks:     defaultPostDamageHooks(),
+		Collision:           DefaultCollisionMap(),
+		OnEvent:             func(eventType string, data interface{}) {}, // Default no-op
 	}
 	w.initWorld()
 	return w
@@ -121,20 +227,18 @@ func NewWorld() *World {
 
 func (w *World) initWorld() {
 	w.spawnMerchant()
+	w.restockMerchant()
+	w.spawnQuestGivers()
+	w.spawnLoreNPCs()
 	w.spawnEnemies()
 	w.spawnInitialElites()
 }
 
 func (w *World) spawnInitialElites() {
 	// Spawn one elite in each area
-	// Level 5 Area (Radius 60-150)
-	w.spawnEliteInArea(5, 60, 150)
-	// Level 10 Area (Radius 160-250)
-	w.spawnEliteInArea(10, 160, 250)
-	// Level 15 Area (Radius 260-350)
-	w.spawnEliteInArea(15, 260, 350)
-	// Level 20+ Area (Radius 360-450)
-	w.spawnEliteInArea(20, 360, 450)
+	for _, zone := range Zones {
+		w.spawnEliteInArea(zone.Level, zone.MinR, zone.MaxR)
+	}
 }
 
 func (w *World) spawnEliteInArea(level int, minR, maxR float64) {
@@ -189,6 +293,7 @@ func (w *World) spawnEliteInArea(level int, minR, maxR float64) {
 	// Actually client checks `isElite` property usually.
 	// Let's just rely on ID for now or add property to Entity struct if we want to be clean.
 	// For now, just spawn it.
+	elite.applyEnemyProfile()
 	w.Entities[elite.ID] = elite
 
 	// Announce Spawn
@@ -199,7 +304,7 @@ func (w *World) spawnEliteInArea(level int, minR, maxR float64) {
 
 func (w *World) spawnMerchant() {
 	merchant := &Entity{
-		ID:      "merchant-1",
+		ID:      MerchantID,
 		Type:    TypeNPC,
 		SubType: "DwarfSalesman",
 		X:       5,
@@ -267,6 +372,7 @@ func (w *World) spawnEnemyGroup(subType string, count int, minRadius, maxRadius
 			State:          "IDLE",
 			AttackCooldown: attackCooldown,
 		}
+		enemy.applyEnemyProfile()
 		w.AddEntity(enemy)
 	}
 }
@@ -280,6 +386,8 @@ func (w *World) AddEntity(e *Entity) {
 func (w *World) RemoveEntity(id string) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
+	w.cancelTradeFor(id, "disconnected")
+	delete(w.Buyback, id)
 	delete(w.Entities, id)
 }
 
@@ -308,9 +416,68 @@ func (w *World) GetEntityCopy(id string) *Entity {
 			newE.Equipment[k] = v
 		}
 	}
+	if e.Stash != nil {
+		newE.Stash = e.Stash.Copy()
+	}
+	if e.Profile != nil {
+		newE.Profile = e.Profile.Copy()
+	}
+	if e.Quests != nil {
+		newE.Quests = make([]QuestProgress, len(e.Quests))
+		for i, q := range e.Quests {
+			q.Progress = append([]int(nil), q.Progress...)
+			newE.Quests[i] = q
+		}
+	}
+	if e.CompletedQuests != nil {
+		newE.CompletedQuests = make(map[string]bool, len(e.CompletedQuests))
+		for k, v := range e.CompletedQuests {
+			newE.CompletedQuests[k] = v
+		}
+	}
+	if e.Talents != nil {
+		newE.Talents = make(map[string]int, len(e.Talents))
+		for k, v := range e.Talents {
+			newE.Talents[k] = v
+		}
+	}
+	if e.Materials != nil {
+		newE.Materials = make(map[string]int, len(e.Materials))
+		for k, v := range e.Materials {
+			newE.Materials[k] = v
+		}
+	}
 	return &newE
 }
 
+// IsElite reports whether an enemy was spawned as an elite.
+func (e *Entity) IsElite() bool {
+	return strings.HasPrefix(e.ID, "elite-")
+}
+
+// findInventoryItem returns the index of the item with the given ID, or -1.
+func (e *Entity) findInventoryItem(itemID string) int {
+	for i := range e.Inventory {
+		if e.Inventory[i].ID == itemID {
+			return i
+		}
+	}
+	return -1
+}
+
+// removeInventoryItem swap-removes an item from the inventory and returns it.
+func (e *Entity) removeInventoryItem(itemID string) (Item, bool) {
+	idx := e.findInventoryItem(itemID)
+	if idx == -1 {
+		return Item{}, false
+	}
+	item := e.Inventory[idx]
+	lastIdx := len(e.Inventory) - 1
+	e.Inventory[idx] = e.Inventory[lastIdx]
+	e.Inventory = e.Inventory[:lastIdx]
+	return item, true
+}
+
 func (w *World) PerformPickup(playerID, lootID string) (*Entity, bool) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
@@ -323,6 +490,9 @@ func (w *World) PerformPickup(playerID, lootID string) (*Entity, bool) {
 	if !ok || loot.Type != TypeLoot {
 		return nil, false
 	}
+	if !loot.lootableBy(playerID, time.Now()) {
+		return nil, false
+	}
 
 	dx := player.X - loot.X
 	dz := player.Z - loot.Z
@@ -331,12 +501,66 @@ func (w *World) PerformPickup(playerID, lootID string) (*Entity, bool) {
 		if loot.LootItem != nil {
 			player.Inventory = append(player.Inventory, *loot.LootItem)
 			delete(w.Entities, lootID)
+			w.questCollect(player, *loot.LootItem)
+			w.recordLoot(player, *loot.LootItem)
 			return player, true
 		}
 	}
 	return nil, false
 }
 
+// MovePlayer applies a client movement update.
+// MovePlayer applies a client position update. teleportAck is the latest
+// server teleport the client has applied; updates sent before it are stale.
+func (w *World) MovePlayer(playerID string, x, y, z, rotation float64, state string, teleportAck int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	e, ok := w.Entities[playerID]
+	if !ok || teleportAck < e.TeleportSeq {
+		return
+	}
+	// Trust the client's movement, but not through terrain
+	if w.Collision != nil {
+		x, z = w.Collision.Resolve(e.X, e.Z, x, z, AgentRadius)
+	}
+	if c := e.Casting; c != nil && math.Hypot(x-c.StartX, z-c.StartZ) > CastMoveTolerance {
+		w.interruptCast(e)
+	}
+	e.X = x
+	e.Y = y
+	e.Z = z
+	e.Rotation = rotation
+	if e.Casting != nil {
+		e.State = "CASTING"
+	} else if state != "" {
+		e.State = state
+	} else {
+		e.State = "MOVING" // Fallback
+	}
+
+	w.questReach(e)
+}
+
+// Teleport is a server-side move sent to the client, which acknowledges Seq
+// in its next move updates.
+type Teleport struct {
+	X   float64 `json:"x"`
+	Z   float64 `json:"z"`
+	Seq int     `json:"seq"`
+}
+
+// teleport moves a player and ignores their move updates until the client
+// acknowledges it. Caller must hold the lock.
+func (w *World) teleport(e *Entity, x, z float64) *Teleport {
+	w.interruptCast(e)
+	e.X, e.Z = x, z
+	e.State = "IDLE"
+	e.IsCharging = false
+	e.TeleportSeq++
+	return &Teleport{X: x, Z: z, Seq: e.TeleportSeq}
+}
+
 func (w *World) PerformEquip(playerID, itemID, slot string) (*Entity, bool) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
@@ -357,7 +581,7 @@ func (w *World) PerformEquip(playerID, itemID, slot string) (*Entity, bool) {
 		}
 	}
 
-	if itemToEquip == nil {
+	if itemToEquip == nil || itemToEquip.Type == ItemGem {
 		return nil, false
 	}
 
@@ -389,15 +613,15 @@ func (w *World) PerformBuyGamble(playerID, slot string) (*Entity, bool) {
 	defer w.mu.Unlock()
 
 	player, ok := w.Entities[playerID]
-	if !ok {
+	if !ok || !w.nearMerchant(player) {
 		return nil, false
 	}
 
-	cost := 500
+	cost := GambleCost
 	if player.Gold < cost {
 		return nil, false
 	}
-	if len(player.Inventory) >= 20 {
+	if len(player.Inventory) >= MaxInventorySize {
 		return nil, false
 	}
 
@@ -417,7 +641,7 @@ func (w *World) PerformSell(playerID, itemID string) (*Entity, bool) {
 	defer w.mu.Unlock()
 
 	player, ok := w.Entities[playerID]
-	if !ok {
+	if !ok || !w.nearMerchant(player) {
 		return nil, false
 	}
 
@@ -435,11 +659,8 @@ func (w *World) PerformSell(playerID, itemID string) (*Entity, bool) {
 		return nil, false
 	}
 
-	value := itemToSell.Value
-	if value <= 0 {
-		value = 1
-	}
-	player.Gold += value
+	player.earnGold(SellPrice(*itemToSell))
+	w.addBuyback(playerID, *itemToSell)
 
 	lastIdx := len(player.Inventory) - 1
 	player.Inventory[invIndex] = player.Inventory[lastIdx]
@@ -505,7 +726,7 @@ func (w *World) Update(dt float64) {
 		if e.Type == TypeEnemy || e.Type == TypeNPC {
 			if e.State == "DEAD" {
 				// Check if Elite
-				if strings.HasPrefix(e.ID, "elite-") {
+				if e.IsElite() {
 					// Elites do not respawn, they are removed after death animation time
 					if time.Since(e.LastAttackTime) > 5*time.Second {
 						delete(w.Entities, id)
@@ -519,6 +740,7 @@ func (w *World) Update(dt float64) {
 					e.Health = e.MaxHealth
 					e.X = e.SpawnX
 					e.Z = e.SpawnZ
+					e.resetAI()
 				}
 				continue
 			}
@@ -526,22 +748,44 @@ func (w *World) Update(dt float64) {
 
 		// --- Projectiles ---
 		if e.Type == TypeProjectile {
-			// Move
+			// Stationary fire patches (Burning Trail)
+			if e.SubType == "FireTrail" {
+				if !w.updateFireTrail(e, enemies) {
+					delete(w.Entities, id)
+				}
+				continue
+			}
+
+			// Move; terrain stops projectiles
+			prevX, prevZ := e.X, e.Z
 			e.X += e.VelX * dt
 			e.Z += e.VelZ * dt
+			if w.Collision != nil && !w.Collision.LineOfSight(prevX, prevZ, e.X, e.Z) {
+				delete(w.Entities, id)
+				continue
+			}
+
+			// Enemy bolts
+			if e.Hostile {
+				if !w.updateHostileProjectile(e, players) {
+					delete(w.Entities, id)
+				}
+				continue
+			}
 
 			// Check Collision with Enemies
 			for _, target := range enemies {
+				if target.State == "DEAD" || target.ID == e.IgnoreID {
+					continue
+				}
 				dx := e.X - target.X
 				dz := e.Z - target.Z
 				dist := math.Sqrt(dx*dx + dz*dz)
 				if dist < (e.Radius + 0.5) { // 0.5 is approx enemy radius
 					// Hit!
 					damage := e.Damage
-					target.Health -= damage
-					if target.Health <= 0 {
-						w.handleDeath(target, w.Entities[e.OwnerID])
-					}
+					owner := w.Entities[e.OwnerID]
+					w.ApplyDamage(owner, target, DamageSpec{Amount: damage, Type: e.Element, Source: e.SubType, OnHit: true, Procs: !e.NoProc})
 
 					// Splash Damage (Fireball)
 					if e.SubType == "Fireball" {
@@ -553,10 +797,7 @@ func (w *World) Update(dt float64) {
 							sdz := e.Z - splashTarget.Z
 							sdist := math.Sqrt(sdx*sdx + sdz*sdz)
 							if sdist < 10.0 {
-								splashTarget.Health -= int(float64(damage) * 0.4)
-								if splashTarget.Health <= 0 {
-									w.handleDeath(splashTarget, w.Entities[e.OwnerID])
-								}
+								w.ApplyDamage(owner, splashTarget, DamageSpec{Amount: int(float64(damage) * 0.4), Type: e.Element, Source: e.SubType})
 							}
 						}
 					}
@@ -576,6 +817,8 @@ func (w *World) Update(dt float64) {
 
 		// --- Player Abilities ---
 		if e.Type == TypePlayer {
+			w.updateCast(e)
+
 			// Fighter Charge
 			if e.IsCharging {
 				dx := e.ChargeTargetX - e.X
@@ -584,7 +827,16 @@ func (w *World) Update(dt float64) {
 				speed := 25.0
 				moveDist := speed * dt
 
-				if moveDist >= dist {
+				if moveDist > dist {
+					moveDist = dist
+				}
+				nextX := e.X + (dx/dist)*moveDist
+				nextZ := e.Z + (dz/dist)*moveDist
+				if dist > 0 && w.Collision != nil && w.Collision.Collides(nextX, nextZ, AgentRadius) {
+					// Slammed into terrain
+					e.IsCharging = false
+					e.State = "IDLE"
+				} else if moveDist >= dist {
 					e.X = e.ChargeTargetX
 					e.Z = e.ChargeTargetZ
 					e.IsCharging = false
@@ -594,6 +846,18 @@ func (w *World) Update(dt float64) {
 					e.Z += (dz / dist) * moveDist
 					e.Rotation = math.Atan2(dx, dz)
 				}
+
+				// Burning Trail (Stampede Greaves)
+				if e.TrailDamage > 0 {
+					tdx := e.X - e.LastTrailX
+					tdz := e.Z - e.LastTrailZ
+					if math.Sqrt(tdx*tdx+tdz*tdz) >= fireTrailSpacing || !e.IsCharging {
+						w.dropFireTrail(e)
+					}
+					if !e.IsCharging {
+						e.TrailDamage = 0
+					}
+				}
 			}
 
 			// Cleric Spirits
@@ -603,16 +867,13 @@ func (w *World) Update(dt float64) {
 				} else {
 					if time.Since(e.LastSpiritTick) >= 500*time.Millisecond {
 						e.LastSpiritTick = time.Now()
-						damage := 10 + (e.BaseStats.Wisdom * 1)
+						damage := e.abilityDamage(10 + (e.BaseStats.Wisdom * 1))
 						for _, target := range enemies {
 							dx := e.X - target.X
 							dz := e.Z - target.Z
 							dist := math.Sqrt(dx*dx + dz*dz)
 							if dist < 16.0 {
-								target.Health -= damage
-								if target.Health <= 0 {
-									w.handleDeath(target, e)
-								}
+								w.ApplyDamage(e, target, DamageSpec{Amount: damage, Type: AbilityDamageType("Spirits"), Source: "Spirits", Procs: true})
 							}
 						}
 					}
@@ -621,201 +882,66 @@ func (w *World) Update(dt float64) {
 		}
 
 		if e.Type == TypeEnemy {
-			// AI Logic
-			var target *Entity
-			minDist := 1000.0 // Far
-
-			// Find nearest player
-			for _, p := range players {
-				// Check if player is in Safe Zone (Town: -50 to 50)
-				if p.X > -50 && p.X < 50 && p.Z > -50 && p.Z < 50 {
-					continue
-				}
-
-				dx := p.X - e.X
-				dz := p.Z - e.Z
-				dist := math.Sqrt(dx*dx + dz*dz)
-				if dist < minDist {
-					minDist = dist
-					target = p
-				}
-			}
-
-			sightRange := 45.0
-			attackRange := 2.5
-			roamRadius := 10.0
-
-			if target != nil && minDist <= sightRange {
-				// Chase or Attack
-				if minDist <= attackRange {
-					// Attack
-					if time.Since(e.LastAttackTime) >= e.AttackCooldown {
-						// Perform Attack
-						damage := e.Damage - target.Defense
-						if damage < 1 {
-							damage = 1
-						}
-						target.Health -= damage
-						e.LastAttackTime = time.Now()
-						e.State = "ATTACKING" // Client can play animation
-
-						if target.Health <= 0 {
-							target.Health = 0
-							target.State = "DEAD"
-							// TODO: Handle player death (respawn logic is usually client request or server timer)
-						}
-					} else {
-						// Waiting for cooldown
-						// Only reset to IDLE if enough time has passed for the attack animation (e.g. 500ms)
-						if time.Since(e.LastAttackTime) > 500*time.Millisecond {
-							if e.State == "ATTACKING" {
-								e.State = "IDLE"
-							}
-						}
-					}
-				} else {
-					// Chase
-					e.TargetX = target.X
-					e.TargetZ = target.Z
-					e.State = "MOVING"
-
-					// Move
-					dx := e.TargetX - e.X
-					dz := e.TargetZ - e.Z
-					dist := math.Sqrt(dx*dx + dz*dz)
-					if dist > 0 {
-						moveDist := e.Speed * dt
-						if moveDist > dist {
-							moveDist = dist
-						}
-						newX := e.X + (dx/dist)*moveDist
-						newZ := e.Z + (dz/dist)*moveDist
-
-						// Prevent entering Safe Zone
-						if newX > -50 && newX < 50 && newZ > -50 && newZ < 50 {
-							// Blocked
-							e.State = "IDLE"
-						} else {
-							e.X = newX
-							e.Z = newZ
-							e.Rotation = math.Atan2(dx, dz)
-						}
-					}
-				}
-			} else {
-				// Roam
-				// If no target or reached target, pick new one
-				dx := e.TargetX - e.X
-				dz := e.TargetZ - e.Z
-				distToTarget := math.Sqrt(dx*dx + dz*dz)
-
-				if distToTarget < 0.5 || (e.TargetX == 0 && e.TargetZ == 0) {
-					// Pick new random target around Spawn Point
-					angle := rand.Float64() * 2 * math.Pi
-					dist := rand.Float64() * roamRadius
-					e.TargetX = e.SpawnX + math.Cos(angle)*dist
-					e.TargetZ = e.SpawnZ + math.Sin(angle)*dist
-					e.State = "MOVING"
-				}
+			w.updateEnemyAI(e, players, dt)
+		}
+	}
 
-				// Move towards roam target
-				dx = e.TargetX - e.X
-				dz = e.TargetZ - e.Z
-				dist := math.Sqrt(dx*dx + dz*dz)
+	// 3. Close trades broken by distance or death
+	w.updateTrades()
 
-				if dist > 0 {
-					moveDist := e.Speed * dt
-					if moveDist > dist {
-						moveDist = dist
-					}
-					newX := e.X + (dx/dist)*moveDist
-					newZ := e.Z + (dz/dist)*moveDist
-
-					// Prevent entering Safe Zone
-					if newX > -50 && newX < 50 && newZ > -50 && newZ < 50 {
-						e.TargetX = e.SpawnX
-						e.TargetZ = e.SpawnZ
-					} else {
-						e.X = newX
-						e.Z = newZ
-						e.Rotation = math.Atan2(dx, dz)
-					}
-				}
-			}
-		}
+	// Merchant Restock
+	if time.Now().After(w.MerchantRestockTime) {
+		w.restockMerchant()
 	}
 
-	// 3. Elite Spawning Logic (Every 5 minutes)
+	// 4. Elite Spawning Logic (Every 5 minutes)
 	if time.Since(w.EliteSpawnTimer) >= 5*time.Minute {
 		w.EliteSpawnTimer = time.Now()
 		// Spawn one random elite
-		type SpawnArea struct {
-			MinR, MaxR float64
-			Level      int
-		}
-		areas := []SpawnArea{
-			{60, 150, 5},
-			{160, 250, 10},
-			{260, 350, 15},
-			{360, 450, 20},
-		}
-		area := areas[rand.Intn(len(areas))]
+		area := Zones[rand.Intn(len(Zones))]
 		w.spawnEliteInArea(area.Level, area.MinR, area.MaxR)
 	}
 }
 
-func (w *World) PerformAttack(attackerID, targetID string) (int, bool) {
+func (w *World) PerformAttack(attackerID, targetID string) (HitResult, bool) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
 	attacker, ok := w.Entities[attackerID]
 	if !ok || attacker.State == "DEAD" {
-		return 0, false
+		return HitResult{}, false
 	}
 
 	target, ok := w.Entities[targetID]
 	if !ok || target.State == "DEAD" {
-		return 0, false
+		return HitResult{}, false
 	}
 
 	// NO PVP: If both are players, return false
 	if attacker.Type == TypePlayer && target.Type == TypePlayer {
-		return 0, false
+		return HitResult{}, false
 	}
 
 	// NO NPC ATTACKS
 	if target.Type == TypeNPC {
-		return 0, false
+		return HitResult{}, false
 	}
 
 	// Check Cooldown
 	if time.Since(attacker.LastAttackTime) < attacker.AttackCooldown {
-		return 0, false
+		return HitResult{}, false
 	}
-
-	// Check Range (Simple distance check)
-	dx := attacker.X - target.X
-	dz := attacker.Z - target.Z
-	dist := math.Sqrt(dx*dx + dz*dz)
-
-	attackRange := 5.0 // Default Melee range
-	switch attacker.SubType {
-	case "Wizard", "Rogue":
-		attackRange = 100.0 // Ranged - effectively infinite
-	case "DwarfSalesman":
-		attackRange = 6.0
+	if attacker.Casting != nil || attacker.isStunned(w.now()) {
+		return HitResult{}, false
 	}
 
-	if dist > attackRange {
-		return 0, false
+	// Check Range and Line of Sight
+	if distance(attacker, target) > AttackRange(attacker.SubType) || !w.hasLineOfSight(attacker, target) {
+		return HitResult{}, false
 	}
 
-	// Apply Damage
-	damage := attacker.Damage - target.Defense
-	if damage < 1 {
-		damage = 1
-	}
-	target.Health -= damage
+	// Resolve and apply damage (death is handled by ApplyDamage)
+	hit := w.weaponHit(attacker, target)
 
 	attacker.LastAttackTime = time.Now()
 	attacker.State = "ATTACKING"
@@ -823,11 +949,20 @@ func (w *World) PerformAttack(attackerID, targetID string) (int, bool) {
 	// Reset state to IDLE after a short delay (handled in Update or client prediction)
 	// For now, we just set it, and next movement will override it.
 
-	if target.Health <= 0 {
-		w.handleDeath(target, attacker)
-	}
+	return hit, true
+}
 
-	return damage, true
+// weaponHit resolves a basic attack and sends it through the damage pipeline.
+// Misses and dodges are still reported. Caller must hold the lock.
+func (w *World) weaponHit(attacker, target *Entity) HitResult {
+	hit := resolveHit(attacker, target)
+	if !hit.Landed() {
+		w.emitDamage(DamageEvent{SourceID: attacker.ID, TargetID: target.ID, Type: hit.Type, Outcome: hit.Outcome}, target)
+		return hit
+	}
+	ev := w.ApplyDamage(attacker, target, DamageSpec{Amount: hit.Damage, Type: hit.Type, Outcome: hit.Outcome, Crit: hit.Crit, OnHit: true, Procs: true})
+	hit.Damage = ev.Amount
+	return hit
 }
 
 func (w *World) PerformAbility(playerID string, targetX, targetZ float64, targetID string) {
@@ -849,12 +984,28 @@ func (w *World) PerformAbility(playerID string, targetX, targetZ float64, target
 	if time.Since(player.LastAbilityTime) < cooldown {
 		return
 	}
+	if player.Casting != nil || player.isStunned(w.now()) {
+		return
+	}
 
+	def, ok := ClassAbilities[player.SubType]
+	if !ok || player.Mana < player.abilityCost(def.ManaCost) {
+		return
+	}
+	if w.startCast(player, Cast{Ability: def.Name, TargetID: targetID, TargetX: targetX, TargetZ: targetZ}) {
+		return
+	}
+	w.releaseAbility(player, targetX, targetZ)
+}
+
+// releaseAbility fires a class ability, after its cast time if it has one.
+// Caller must hold the lock.
+func (w *World) releaseAbility(player *Entity, targetX, targetZ float64) {
 	// Class Specific Logic
 	switch player.SubType {
 	case "Fighter":
 		// Charge
-		cost := 20
+		cost := player.abilityCost(ClassAbilities["Fighter"].ManaCost)
 		if player.Mana >= cost {
 			player.Mana -= cost
 			player.IsCharging = true
@@ -863,11 +1014,12 @@ func (w *World) PerformAbility(playerID string, targetX, targetZ float64, target
 			player.State = "ATTACKING" // Or special state?
 			player.AbilityCooldown = 5 * time.Second
 			player.LastAbilityTime = time.Now()
+			w.triggerProcs(player, nil, ProcOnAbility, "Charge")
 		}
 
 	case "Wizard":
 		// Fireball
-		cost := 30
+		cost := player.abilityCost(ClassAbilities["Wizard"].ManaCost)
 		if player.Mana >= cost {
 			player.Mana -= cost
 
@@ -882,7 +1034,7 @@ func (w *World) PerformAbility(playerID string, targetX, targetZ float64, target
 			velX := (dx / dist) * 20.0 // Speed 20
 			velZ := (dz / dist) * 20.0
 
-			damage := 20 + (player.Stats.Intelligence * 2)
+			damage := player.abilityDamage(20 + (player.Stats.Intelligence * 2))
 
 			proj := &Entity{
 				ID:       fmt.Sprintf("proj-%d", time.Now().UnixNano()),
@@ -897,17 +1049,19 @@ func (w *World) PerformAbility(playerID string, targetX, targetZ float64, target
 				Damage:   damage,
 				OwnerID:  player.ID,
 				Rotation: math.Atan2(velX, velZ),
+				Element:  AbilityDamageType("Fireball"),
 			}
 			w.Entities[proj.ID] = proj
 
 			player.State = "ATTACKING"
 			player.AbilityCooldown = 2 * time.Second
 			player.LastAbilityTime = time.Now()
+			w.triggerProcs(player, nil, ProcOnAbility, "Fireball")
 		}
 
 	case "Rogue":
 		// Throw Dagger
-		cost := 15
+		cost := player.abilityCost(ClassAbilities["Rogue"].ManaCost)
 		if player.Mana >= cost {
 			player.Mana -= cost
 
@@ -921,7 +1075,7 @@ func (w *World) PerformAbility(playerID string, targetX, targetZ float64, target
 			velX := (dx / dist) * 35.0 // Speed 35
 			velZ := (dz / dist) * 35.0
 
-			damage := 15 + int(float64(player.Stats.Dexterity)*1.5)
+			damage := player.abilityDamage(15 + int(float64(player.Stats.Dexterity)*1.5))
 
 			proj := &Entity{
 				ID:       fmt.Sprintf("proj-%d", time.Now().UnixNano()),
@@ -936,17 +1090,19 @@ func (w *World) PerformAbility(playerID string, targetX, targetZ float64, target
 				Damage:   damage,
 				OwnerID:  player.ID,
 				Rotation: math.Atan2(velX, velZ),
+				Element:  AbilityDamageType("Dagger"),
 			}
 			w.Entities[proj.ID] = proj
 
 			player.State = "ATTACKING"
 			player.AbilityCooldown = 1 * time.Second
 			player.LastAbilityTime = time.Now()
+			w.triggerProcs(player, nil, ProcOnAbility, "Dagger")
 		}
 
 	case "Cleric":
 		// Guardian Spirits
-		cost := 40
+		cost := player.abilityCost(ClassAbilities["Cleric"].ManaCost)
 		if player.Mana >= cost {
 			player.Mana -= cost
 			player.SpiritsActive = true
@@ -954,6 +1110,7 @@ func (w *World) PerformAbility(playerID string, targetX, targetZ float64, target
 			player.State = "ATTACKING"
 			player.AbilityCooldown = 10 * time.Second
 			player.LastAbilityTime = time.Now()
+			w.triggerProcs(player, nil, ProcOnAbility, "Spirits")
 		}
 	}
 }
@@ -966,57 +1123,70 @@ func (w *World) handleDeath(target *Entity, attacker *Entity) {
 	target.Health = 0
 	target.State = "DEAD"
 	target.LastAttackTime = time.Now()
+	threat := target.Threat
+	target.Threat = nil
+	target.Absorb = 0
+
+	ev := CombatEvent{Type: EventDeath, TargetID: target.ID, X: target.X, Z: target.Z}
+	if attacker != nil {
+		ev.SourceID = attacker.ID
+	}
+	w.queueEvent(ev)
+
+	target.Casting = nil
+	if target.Type == TypePlayer {
+		target.IsCharging = false
+		target.SpiritsActive = false
+		target.applyDeathDurability()
+		target.recordDeath()
+
+		// Enemies stop hunting a dead player
+		for _, e := range w.Entities {
+			delete(e.Threat, target.ID)
+		}
+	}
 
 	if attacker != nil && attacker.Type == TypePlayer && target.Type == TypeEnemy {
 		// XP
-		xpReward := target.Level*10 + 10
-		attacker.Experience += xpReward
-		if attacker.MaxExperience == 0 {
-			attacker.MaxExperience = 100
+		xp := target.Level*10 + 10
+		w.awardExperience(attacker, xp, "kill")
+		w.awardAssists(target, attacker, threat, xp)
+
+		// Quest progress, realm restoration and lifetime stats
+		w.questKill(attacker, target)
+		w.recordRegionKill(target)
+		w.recordKill(attacker, target)
+
+		// On-kill procs
+		w.triggerProcs(attacker, target, ProcOnKill, "")
+
+		// Loot
+		table := LootTableFor(target)
+		zone := ZoneAt(target.X, target.Z)
+		attacker.earnGold(RollGold(table, zone, target.Level))
+
+		dropCount := table.Guaranteed
+		if dropCount == 0 && target.Level > 0 && rand.Float64() < table.DropChance {
+			dropCount = 1
 		}
 
-		for attacker.Experience >= attacker.MaxExperience {
-			if attacker.Level >= 100 {
-				attacker.Experience = attacker.MaxExperience
-				break
+		drops := make([]*Item, 0, dropCount+1)
+		for i := 0; i < dropCount; i++ {
+			item := GenerateLootFromTable(table, zone, target.Level, attacker.MagicFind, attacker.LootPity)
+
+			// Bad-luck protection counts every drop since the last Legendary
+			if item.Rarity == RarityLegendary {
+				attacker.LootPity = 0
+			} else {
+				attacker.LootPity++
 			}
-			attacker.Experience -= attacker.MaxExperience
-			attacker.Level++
-			// Exponential Curve: 100 * (1.2 ^ (Level-1))
-			attacker.MaxExperience = int(100 * math.Pow(1.2, float64(attacker.Level-1)))
-
-			// Update Base Stats
-			attacker.BaseStats.Vitality += 2
-			attacker.BaseStats.Strength += 2
-			attacker.BaseStats.Dexterity += 1
-			attacker.BaseStats.Intelligence += 1
-			attacker.BaseStats.Wisdom += 1
-
-			attacker.RecalculateStats()
-			attacker.Health = attacker.MaxHealth
-		} // Loot
-		gold := 0
-		if target.Level > 0 {
-			gold = rand.Intn(target.Level*10) + 10
+			drops = append(drops, item)
 		}
-		attacker.Gold += gold
-
-		// Check if Elite
-		isElite := strings.HasPrefix(target.ID, "elite-")
-		dropCount := 0
-		if isElite {
-			dropCount = 3 // Elites drop 3 items guaranteed
-		} else if rand.Float64() < 0.5 && target.Level > 0 {
-			dropCount = 1 // Normal enemies have 50% chance for 1 item
+		if target.Level > 0 && rand.Float64() < table.GemChance {
+			drops = append(drops, GenerateGem(target.Level))
 		}
 
-		for i := 0; i < dropCount; i++ {
-			var item *Item
-			if isElite {
-				item = GenerateEliteLoot(target.Level)
-			} else {
-				item = GenerateLoot(target.Level)
-			}
+		for i, item := range drops {
 
 			// Offset loot slightly so they don't stack perfectly
 			offsetX := (rand.Float64() - 0.5) * 1.0
@@ -1032,9 +1202,39 @@ func (w *World) handleDeath(target *Entity, attacker *Entity) {
 				LootItem: item,
 				LootTime: time.Now(),
 			}
+			w.assignLootOwner(lootEntity, attacker)
 			w.Entities[lootEntity.ID] = lootEntity
+			w.queueEvent(CombatEvent{Type: EventLootDrop, SourceID: attacker.ID, TargetID: lootEntity.ID, Item: item.Name, Rarity: item.Rarity, X: lootEntity.X, Z: lootEntity.Z})
+		}
+	}
+}
+
+// grantExperience adds XP and applies any level ups. Returns the levels gained.
+func (e *Entity) grantExperience(amount int) int {
+	levels := 0
+	e.Experience += amount
+	if e.MaxExperience == 0 {
+		e.MaxExperience = 100
+	}
+
+	for e.Experience >= e.MaxExperience {
+		if e.Level >= 100 {
+			e.Experience = e.MaxExperience
+			break
 		}
+		e.Experience -= e.MaxExperience
+		e.Level++
+		levels++
+		// Exponential Curve: 100 * (1.2 ^ (Level-1))
+		e.MaxExperience = int(100 * math.Pow(1.2, float64(e.Level-1)))
+
+		// Class growth and unspent points
+		e.applyLevelGrowth()
+
+		e.RecalculateStats()
+		e.Health = e.MaxHealth
 	}
+	return levels
 }
 
 func (w *World) GetState() map[string]*Entity {
@@ -1112,16 +1312,55 @@ func (e *Entity) RecalculateStats() {
 	flatDamage := 0
 	flatDefense := 0
 
-	// Add Equipment Stats
+	// Sum every stat key from equipment and active set bonuses
+	totals := make(map[string]int)
+	var procs []ProcDef
 	for _, item := range e.Equipment {
-		totalStr += item.Stats["strength"]
-		totalDex += item.Stats["dexterity"]
-		totalInt += item.Stats["intelligence"]
-		totalWis += item.Stats["wisdom"]
-		totalVit += item.Stats["vitality"]
-
-		flatDamage += item.Stats["damage"]
-		flatDefense += item.Stats["defense"]
+		if item.Broken() {
+			continue
+		}
+		for k, v := range item.Stats {
+			totals[k] += v
+		}
+		if item.UniqueID != "" {
+			if def := GetUnique(item.UniqueID); def != nil {
+				procs = append(procs, def.Proc)
+			}
+		}
+	}
+	bonuses, setPieces := activeSetBonuses(e.Equipment)
+	for _, b := range bonuses {
+		for k, v := range b.Stats {
+			totals[k] += v
+		}
+		if b.Proc != nil {
+			procs = append(procs, *b.Proc)
+		}
+	}
+	e.Procs = procs
+	e.SetPieces = setPieces
+	e.talentStats(totals)
+
+	totalStr += totals["strength"]
+	totalDex += totals["dexterity"]
+	totalInt += totals["intelligence"]
+	totalWis += totals["wisdom"]
+	totalVit += totals["vitality"]
+
+	flatDamage += totals[StatDamage]
+	flatDefense += totals[StatDefense]
+
+	// Affix totals (percent stats are whole points)
+	critPct := totals[StatCritChance]
+	attackSpeedPct := totals[StatAttackSpeed]
+	moveSpeedPct := totals[StatMoveSpeed]
+	lifeOnHit := totals[StatLifeOnHit]
+	magicFindPct := totals[StatMagicFind]
+	resist := Resistances{
+		Fire:  totals[StatFireResist],
+		Water: totals[StatWaterResist],
+		Earth: totals[StatEarthResist],
+		Air:   totals[StatAirResist],
 	}
 
 	// Update Total Stats
@@ -1141,13 +1380,14 @@ func (e *Entity) RecalculateStats() {
 	e.HpRegen = float64(totalVit) * 0.5
 
 	e.MaxMana = (totalInt * 10) + levelBonus
-	e.CooldownReduction = math.Min(0.5, float64(totalInt)*0.01)
+	e.CooldownReduction = math.Min(0.5, float64(totalInt)*0.01+float64(totals[StatAbilityCooldown])/100.0)
 
 	e.Damage = (totalStr * 2) + flatDamage
 	e.Defense = flatDefense
 
 	// Speed Calculation
 	e.Speed = (3.0 + (float64(totalDex) * 0.5)) * 1.2
+	e.Speed *= 1.0 + float64(moveSpeedPct)/100.0
 
 	// Cap Speed (Max = 3x Speed at 10 Dex)
 	refDex := 10.0
@@ -1159,10 +1399,19 @@ func (e *Entity) RecalculateStats() {
 	}
 
 	e.AttackSpeed = 1.0 + (float64(totalDex)/5.0)*0.05
+	e.AttackSpeed *= 1.0 + float64(attackSpeedPct)/100.0
+	e.AttackCooldown = attackCooldown(e.Equipment["mainHand"], e.AttackSpeed)
 
 	e.ManaRegen = float64(totalWis) * 0.5
 	e.CastSpeed = 1.0 + (float64(totalWis)/5.0)*0.01
 
+	e.CritChance, e.DodgeChance, e.BlockChance = combatChances(e.Stats, critPct, isShield(e.Equipment["offHand"]))
+	e.LifeOnHit = lifeOnHit
+	e.AbilityDamageBonus = float64(totals[StatAbilityDamage]) / 100.0
+	e.AbilityCostReduction = math.Min(0.5, float64(totals[StatAbilityCost])/100.0)
+	e.Resistances = resist
+	e.MagicFind = math.Min(MaxMagicFind, float64(magicFindPct)/100.0)
+
 	if e.Mana > e.MaxMana {
 		e.Mana = e.MaxMana
 	}
@@ -41,15 +41,16 @@ func TestAddRemoveEntity(t *testing.T) {
 
 func TestWorldUpdate(t *testing.T) {
 	w := NewWorld()
-	// Add a moving enemy
+	// Add a moving enemy (outside the town safe zone, which enemies cannot enter)
 	e := &Entity{
 		ID:      "enemy-1",
 		Type:    TypeEnemy,
 		State:   "MOVING",
-		X:       0,
+		X:       200,
 		Y:       0,
 		Z:       0,
-		TargetX: 10,
+		SpawnX:  200,
+		TargetX: 210,
 		TargetZ: 0,
 		Speed:   1.0,
 	}
@@ -58,12 +59,12 @@ func TestWorldUpdate(t *testing.T) {
 	// Update for 1 second
 	w.Update(1.0)
 
-	// Should have moved towards (10, 0)
-	// New X should be approx 1.0
-	if e.X <= 0 {
+	// Should have moved towards (210, 0)
+	// New X should be approx 201.0
+	if e.X <= 200 {
 		t.Errorf("Entity did not move. X = %f", e.X)
 	}
-	if e.X > 1.1 {
+	if e.X > 201.1 {
 		t.Errorf("Entity moved too far. X = %f", e.X)
 	}
 }
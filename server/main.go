@@ -3,6 +3,7 @@ package main
 import (
 	"encoding/json"
 	"flag"
+	"fmt"
 	"log"
 	"math"
 	"math/rand"
@@ -56,6 +57,8 @@ type Client struct {
 	send     chan []byte
 	playerID string
 	username string
+	mailbox  chan struct{} // Mail waiting to be claimed on the client's own goroutine
+	saves    chan struct{} // Save requested by another player's action (trades)
 }
 
 // Message types
@@ -65,7 +68,7 @@ const (
 	MsgRegister  = "register"
 	MsgMove      = "move"
 	MsgAttack    = "attack"
-	MsgDamage    = "damage"
+	MsgCombat    = "combat" // Server -> client: this tick's combat events within view distance
 	MsgChat      = "chat"
 	MsgState     = "state"
 	MsgError     = "error"
@@ -76,6 +79,58 @@ const (
 	MsgBuyGamble = "buy_gamble"
 	MsgSell      = "sell"
 	MsgSocial    = "social"
+
+	MsgStash         = "stash"
+	MsgStashDeposit  = "stash_deposit"
+	MsgStashWithdraw = "stash_withdraw"
+
+	MsgTradeRequest  = "trade_request"
+	MsgTradeAccept   = "trade_accept"
+	MsgTradeOffer    = "trade_offer"
+	MsgTradeLock     = "trade_lock"
+	MsgTradeConfirm  = "trade_confirm"
+	MsgTradeCancel   = "trade_cancel"
+	MsgTrade         = "trade"          // Server -> client: current trade window
+	MsgTradeComplete = "trade_complete" // Server -> client: swap executed
+
+	MsgAuctionList    = "auction_list"
+	MsgAuctionSearch  = "auction_search"
+	MsgAuctionBuy     = "auction_buy"
+	MsgAuctionCancel  = "auction_cancel"
+	MsgAuctionResults = "auction_results" // Server -> client: search results
+	MsgAuctionSold    = "auction_sold"    // Server -> client: one of your listings sold
+
+	MsgMerchant = "merchant" // Request/receive merchant stock and buyback list
+	MsgBuy      = "buy"
+	MsgBuyback  = "buyback"
+
+	MsgSocket      = "socket"
+	MsgUnsocket    = "unsocket"
+	MsgCombineGems = "combine_gems"
+
+	MsgRepair    = "repair"
+	MsgSalvage   = "salvage"
+	MsgMaterials = "materials" // Server -> client: crafting material counts
+	MsgEnchant   = "enchant"
+
+	MsgTalents       = "talents" // Request/receive points and learned talents
+	MsgAllocateStats = "allocate_stats"
+	MsgLearnTalent   = "learn_talent"
+	MsgRespec        = "respec"
+
+	MsgQuests       = "quests" // Request/receive the quest log
+	MsgQuestAccept  = "quest_accept"
+	MsgQuestAbandon = "quest_abandon"
+	MsgQuestTurnIn  = "quest_turn_in"
+	MsgQuestUpdate  = "quest_update" // Server -> client: objective progress changed
+
+	MsgInteract       = "interact"        // Start talking to an NPC
+	MsgDialogueChoice = "dialogue_choice" // Pick a choice on the current node
+	MsgDialogue       = "dialogue"        // Server -> client: node to show (null closes)
+	MsgTeleport       = "teleport"        // Server -> client: player was moved; ack seq in move updates
+	MsgRegions        = "regions"         // Request/receive realm restoration progress
+
+	MsgProfile = "profile" // Request/receive achievements and lifetime stats
 )
 
 type Message struct {
@@ -105,6 +160,7 @@ type MovePayload struct {
 	Z        float64 `json:"z"`
 	Rotation float64 `json:"rotation"`
 	State    string  `json:"state"`
+	Teleport int     `json:"teleport"` // Latest server teleport applied by the client
 }
 
 type AttackPayload struct {
@@ -123,21 +179,114 @@ type SellPayload struct {
 	ItemID string `json:"itemId"`
 }
 
+type BuyPayload struct {
+	ItemID string `json:"itemId"`
+}
+
 type EquipPayload struct {
 	ItemID string `json:"itemId"`
 	Slot   string `json:"slot"`
 }
 
+type SocketPayload struct {
+	ItemID string `json:"itemId"`
+	GemID  string `json:"gemId"`  // socket
+	Socket int    `json:"socket"` // unsocket: socket index
+}
+
+type RepairPayload struct {
+	ItemID string `json:"itemId"` // Empty repairs everything
+}
+
+type EnchantPayload struct {
+	ItemID string `json:"itemId"`
+	Affix  int    `json:"affix"` // Index into the item's affixes
+}
+
+type TalentPayload struct {
+	TalentID string `json:"talentId"`
+}
+
+type QuestPayload struct {
+	QuestID string `json:"questId"`
+}
+
+type InteractPayload struct {
+	NPCID string `json:"npcId"`
+}
+
+type DialogueChoicePayload struct {
+	NPCID  string `json:"npcId"`
+	NodeID string `json:"nodeId"`
+	Choice int    `json:"choice"`
+}
+
+type TalentsPayload struct {
+	StatPoints   int            `json:"statPoints"`
+	TalentPoints int            `json:"talentPoints"`
+	Talents      map[string]int `json:"talents"`
+	RespecCost   int            `json:"respecCost"`
+}
+
+type CombineGemsPayload struct {
+	GemIDs []string `json:"gemIds"`
+}
+
+type StashPayload struct {
+	ItemID string `json:"itemId"`
+	Tab    int    `json:"tab"`
+}
+
+type TradeTargetPayload struct {
+	TargetID string `json:"targetId"`
+}
+
+type TradeOfferPayload struct {
+	ItemIDs []string `json:"itemIds"`
+	Gold    int      `json:"gold"`
+}
+
+type TradeRequestNotice struct {
+	FromID   string `json:"fromId"`
+	FromName string `json:"fromName"`
+}
+
+type TradeCancelNotice struct {
+	TradeID string `json:"tradeId"`
+	Reason  string `json:"reason"`
+}
+
+type AuctionListPayload struct {
+	ItemID string `json:"itemId"`
+	Price  int    `json:"price"`
+	Hours  int    `json:"hours"`
+}
+
+type AuctionSearchPayload struct {
+	Slot     string `json:"slot"`
+	Rarity   string `json:"rarity"`
+	MinLevel int    `json:"minLevel"`
+	MaxLevel int    `json:"maxLevel"`
+}
+
+type AuctionListingPayload struct {
+	ListingID string `json:"listingId"`
+}
+
+// AuctionListing is the client view of a database listing
+type AuctionListing struct {
+	ID        string    `json:"id"`
+	Seller    string    `json:"seller"`
+	Item      game.Item `json:"item"`
+	Price     int       `json:"price"`
+	ExpiresAt int64     `json:"expiresAt"` // Unix seconds
+}
+
 type AbilityPayload struct {
 	TargetX  float64 `json:"targetX"`
 	TargetZ  float64 `json:"targetZ"`
 	TargetID string  `json:"targetId"`
-}
-
-type DamagePayload struct {
-	TargetID string `json:"targetId"`
-	Amount   int    `json:"amount"`
-	SourceID string `json:"sourceId"`
+	Ability  string  `json:"ability,omitempty"` // Support ability ID; empty casts the class ability
 }
 
 type ChatPayload struct {
@@ -154,6 +303,10 @@ var clients = make(map[*Client]bool)
 var activeSessions = make(map[string]*Client)
 var sessionsMu sync.Mutex
 var broadcast = make(chan BroadcastMessage)
+
+// Radius of entities and combat events sent to each client
+const viewDistance = 60.0
+
 var register = make(chan *Client)
 var unregister = make(chan *Client)
 
@@ -174,7 +327,7 @@ func main() {
 
 	// Set up World Event Callback
 	world.OnEvent = func(eventType string, data interface{}) {
-		if eventType == "elite_spawn" {
+		if eventType == "elite_spawn" || eventType == "region_restored" || eventType == "achievement" {
 			msgText, ok := data.(string)
 			if !ok {
 				return
@@ -191,6 +344,20 @@ func main() {
 			}
 			dataBytes, _ := json.Marshal(outMsg)
 			broadcast <- BroadcastMessage{Type: MsgChat, Data: dataBytes}
+		} else if eventType == "trade_cancel" {
+			ev, ok := data.(game.TradeCancel)
+			if !ok {
+				return
+			}
+			// Called with the world locked; notify from a goroutine so we never
+			// take sessionsMu while holding the world lock.
+			go notifyTrade(ev.Trade, MsgTradeCancel, TradeCancelNotice{TradeID: ev.Trade.ID, Reason: ev.Reason})
+		} else if eventType == "quest_update" {
+			ev, ok := data.(game.QuestUpdate)
+			if !ok {
+				return
+			}
+			go notifyPlayer(ev.PlayerID, MsgQuestUpdate, ev.Quest)
 		}
 	}
 
@@ -200,6 +367,7 @@ func main() {
 		for range ticker.C {
 			world.Update(0.05)
 			broadcastState()
+			broadcastCombatEvents(world.DrainEvents())
 		}
 	}()
 
@@ -222,6 +390,14 @@ func main() {
 		}
 	}()
 
+	// Auction Expiry Sweeper (Every 1 minute)
+	go func() {
+		ticker := time.NewTicker(1 * time.Minute)
+		for range ticker.C {
+			sweepAuctions()
+		}
+	}()
+
 	// Graceful Shutdown
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
@@ -307,8 +483,10 @@ func serveWs(w http.ResponseWriter, r *http.Request) {
 	}
 
 	client := &Client{
-		conn: c,
-		send: make(chan []byte, 64), // Reduced buffer size to prevent lag accumulation
+		conn:    c,
+		send:    make(chan []byte, 64), // Reduced buffer size to prevent lag accumulation
+		mailbox: make(chan struct{}, 1),
+		saves:   make(chan struct{}, 1),
 	}
 	register <- client
 
@@ -326,22 +504,58 @@ func (c *Client) readPump() {
 	c.conn.SetReadDeadline(time.Now().Add(pongWait))
 	c.conn.SetPongHandler(func(string) error { c.conn.SetReadDeadline(time.Now().Add(pongWait)); return nil })
 
-	for {
-		_, message, err := c.conn.ReadMessage()
-		if err != nil {
-			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-				log.Printf("error: %v", err)
+	// Messages are read on their own goroutine so mail delivered by other
+	// players is handled here, alongside this client's own messages
+	messages := make(chan Message)
+	go func() {
+		defer close(messages)
+		for {
+			_, message, err := c.conn.ReadMessage()
+			if err != nil {
+				if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+					log.Printf("error: %v", err)
+				}
+				return
 			}
-			break
+
+			var msg Message
+			if err := json.Unmarshal(message, &msg); err != nil {
+				log.Println("json unmarshal:", err)
+				continue
+			}
+			messages <- msg
 		}
+	}()
 
-		var msg Message
-		if err := json.Unmarshal(message, &msg); err != nil {
-			log.Println("json unmarshal:", err)
-			continue
+	for {
+		select {
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+			c.handleMessage(msg)
+		case <-c.mailbox:
+			deliverMailbox(c)
+		case <-c.saves:
+			savePlayer(c)
 		}
+	}
+}
 
-		c.handleMessage(msg)
+// notifyMailbox asks a client to claim its mailbox on its own goroutine, so
+// all of one player's saves happen in one place.
+func (c *Client) notifyMailbox() {
+	select {
+	case c.mailbox <- struct{}{}:
+	default: // A claim is already pending
+	}
+}
+
+// requestSave asks a client to save its player on its own goroutine.
+func (c *Client) requestSave() {
+	select {
+	case c.saves <- struct{}{}:
+	default: // A save is already pending
 	}
 }
 
@@ -523,25 +737,25 @@ func (c *Client) handleMessage(msg Message) {
 		c.playerID = playerID
 
 		entity := &game.Entity{
-			ID:             playerID,
-			Name:           c.username,
-			Type:           game.TypePlayer,
-			SubType:        char.Class,
-			X:              char.X,
-			Y:              char.Y,
-			Z:              char.Z,
-			Health:         char.Stats.Vitality * 10,
-			MaxHealth:      char.Stats.Vitality * 10,
-			Mana:           char.Stats.Intelligence * 10,
-			MaxMana:        char.Stats.Intelligence * 10,
-			Level:          char.Level,
-			Experience:     char.XP,
-			MaxExperience:  int(100 * math.Pow(1.2, float64(char.Level-1))),
-			Gold:           char.Gold,
-			State:          "IDLE",
-			Damage:         char.Stats.Strength * 2,
-			Defense:        0,
-			AttackCooldown: 1000 * time.Millisecond,
+			ID:            playerID,
+			Name:          c.username,
+			Type:          game.TypePlayer,
+			SubType:       char.Class,
+			X:             char.X,
+			Y:             char.Y,
+			Z:             char.Z,
+			Health:        char.Stats.Vitality * 10,
+			MaxHealth:     char.Stats.Vitality * 10,
+			Mana:          char.Stats.Intelligence * 10,
+			MaxMana:       char.Stats.Intelligence * 10,
+			Level:         char.Level,
+			Experience:    char.XP,
+			MaxExperience: int(100 * math.Pow(1.2, float64(char.Level-1))),
+			Gold:          char.Gold,
+			LootPity:      char.LootPity,
+			State:         "IDLE",
+			Damage:        char.Stats.Strength * 2,
+			Defense:       0,
 			BaseStats: game.Stats{
 				Strength:     char.Stats.Strength,
 				Dexterity:    char.Stats.Dexterity,
@@ -556,20 +770,7 @@ func (c *Client) handleMessage(msg Message) {
 			log.Printf("Loading inventory for %s: %d items", c.username, len(char.Inventory))
 			entity.Inventory = make([]game.Item, len(char.Inventory))
 			for i, dbItem := range char.Inventory {
-				// Manual conversion or JSON marshal/unmarshal hack
-				// Let's do manual for safety
-				entity.Inventory[i] = game.Item{
-					ID:          dbItem.ID,
-					Name:        dbItem.Name,
-					Type:        game.ItemType(dbItem.Type),
-					Rarity:      game.ItemRarity(dbItem.Rarity),
-					Slot:        dbItem.Slot,
-					Level:       dbItem.Level,
-					Value:       dbItem.Value,
-					Icon:        dbItem.Icon,
-					Description: dbItem.Description,
-					Stats:       dbItem.Stats,
-				}
+				entity.Inventory[i] = toGameItem(dbItem)
 			}
 		}
 
@@ -577,24 +778,50 @@ func (c *Client) handleMessage(msg Message) {
 		if len(char.Equipment) > 0 {
 			entity.Equipment = make(map[string]game.Item)
 			for slot, dbItem := range char.Equipment {
-				entity.Equipment[slot] = game.Item{
-					ID:          dbItem.ID,
-					Name:        dbItem.Name,
-					Type:        game.ItemType(dbItem.Type),
-					Rarity:      game.ItemRarity(dbItem.Rarity),
-					Slot:        dbItem.Slot,
-					Level:       dbItem.Level,
-					Value:       dbItem.Value,
-					Icon:        dbItem.Icon,
-					Description: dbItem.Description,
-					Stats:       dbItem.Stats,
-				}
+				entity.Equipment[slot] = toGameItem(dbItem)
+			}
+		}
+
+		// Account stash is shared across characters
+		entity.Stash = toGameStash(user.Stash)
+		entity.Profile = toGameProfile(user.Profile)
+		entity.Materials = char.Materials
+
+		// Progression
+		entity.StatPoints = char.StatPoints
+		entity.TalentPoints = char.TalentPoints
+		entity.Talents = char.Talents
+		entity.AllocatedStats = game.Stats{
+			Strength:     char.AllocatedStats.Strength,
+			Dexterity:    char.AllocatedStats.Dexterity,
+			Intelligence: char.AllocatedStats.Intelligence,
+			Wisdom:       char.AllocatedStats.Wisdom,
+			Vitality:     char.AllocatedStats.Vitality,
+		}
+		entity.BackfillPoints()
+
+		// Quests
+		for _, q := range char.Quests {
+			entity.Quests = append(entity.Quests, game.QuestProgress{QuestID: q.QuestID, Progress: q.Progress, Complete: q.Complete})
+		}
+		if len(char.CompletedQuests) > 0 {
+			entity.CompletedQuests = make(map[string]bool, len(char.CompletedQuests))
+			for _, id := range char.CompletedQuests {
+				entity.CompletedQuests[id] = true
 			}
 		}
 
 		entity.RecalculateStats()
 		world.AddEntity(entity)
 
+		// Collect auction gold and returned items delivered while offline
+		deliverMailbox(c)
+
+		if len(entity.Materials) > 0 {
+			c.sendMessage(MsgMaterials, entity.Materials)
+		}
+		c.sendMessage(MsgTalents, talentsView(entity))
+
 		// Send initial inventory
 		if len(entity.Inventory) > 0 {
 			invPayload, _ := json.Marshal(entity.Inventory)
@@ -614,19 +841,7 @@ func (c *Client) handleMessage(msg Message) {
 		if err := json.Unmarshal(msg.Payload, &payload); err != nil {
 			return
 		}
-		// Authoritative movement validation should happen here
-		// For now, trust client but update world state
-		if e := world.GetEntity(c.playerID); e != nil {
-			e.X = payload.X
-			e.Y = payload.Y
-			e.Z = payload.Z
-			e.Rotation = payload.Rotation
-			if payload.State != "" {
-				e.State = payload.State
-			} else {
-				e.State = "MOVING" // Fallback
-			}
-		}
+		world.MovePlayer(c.playerID, payload.X, payload.Y, payload.Z, payload.Rotation, payload.State, payload.Teleport)
 
 	case MsgAttack:
 		if c.playerID == "" {
@@ -637,22 +852,8 @@ func (c *Client) handleMessage(msg Message) {
 			return
 		}
 
-		damage, success := world.PerformAttack(c.playerID, payload.TargetID)
-		if success {
-			// Broadcast damage event
-			dmgPayload := DamagePayload{
-				TargetID: payload.TargetID,
-				Amount:   damage,
-				SourceID: c.playerID,
-			}
-			b, _ := json.Marshal(dmgPayload)
-			outMsg := Message{
-				Type:    MsgDamage,
-				Payload: b,
-			}
-			data, _ := json.Marshal(outMsg)
-			broadcast <- BroadcastMessage{Type: MsgDamage, Data: data}
-		}
+		// Hits, misses and kills reach clients through the combat event stream
+		world.PerformAttack(c.playerID, payload.TargetID)
 
 	case MsgPickup:
 		if c.playerID == "" {
@@ -683,6 +884,10 @@ func (c *Client) handleMessage(msg Message) {
 		if err := json.Unmarshal(msg.Payload, &payload); err != nil {
 			return
 		}
+		if payload.Ability != "" {
+			world.PerformSupportAbility(c.playerID, payload.Ability, payload.TargetID)
+			return
+		}
 		world.PerformAbility(c.playerID, payload.TargetX, payload.TargetZ, payload.TargetID)
 
 	case MsgChat:
@@ -768,127 +973,809 @@ func (c *Client) handleMessage(msg Message) {
 			}
 			b, _ := json.Marshal(msg)
 			c.send <- b
-		}
 
-	case MsgSocial:
-		// Gather online players
-		var playerList []SocialEntry
-		sessionsMu.Lock()
-		for _, client := range activeSessions {
-			if client.playerID != "" {
-				entity := world.GetEntity(client.playerID)
-				if entity != nil {
-					playerList = append(playerList, SocialEntry{
-						Name:  entity.Name,
-						Class: entity.SubType,
-						Level: entity.Level,
-					})
-				}
+			// Sold item shows up in the buyback list
+			if view, ok := world.GetMerchantView(c.playerID); ok {
+				c.sendMessage(MsgMerchant, view)
 			}
 		}
-		sessionsMu.Unlock()
 
-		payload, _ := json.Marshal(playerList)
-		msg := Message{
-			Type:    MsgSocial,
-			Payload: payload,
+	case MsgMerchant:
+		if c.playerID == "" {
+			return
+		}
+		if view, ok := world.GetMerchantView(c.playerID); ok {
+			c.sendMessage(MsgMerchant, view)
 		}
-		b, _ := json.Marshal(msg)
-		c.send <- b
-	}
-}
 
-func (c *Client) sendError(msg string) {
-	defer func() {
-		if r := recover(); r != nil {
-			log.Printf("Recovered from sendError panic: %v", r)
+	case MsgBuy, MsgBuyback:
+		if c.playerID == "" {
+			return
+		}
+		var payload BuyPayload
+		if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+			return
 		}
-	}()
-	m := Message{
-		Type:    MsgError,
-		Payload: json.RawMessage(`"` + msg + `"`),
-	}
-	b, _ := json.Marshal(m)
-	c.send <- b
-}
 
-func broadcastState() {
-	// Iterate over active sessions and send custom state to each
-	sessionsMu.Lock()
-	defer sessionsMu.Unlock()
+		var success bool
+		if msg.Type == MsgBuy {
+			_, success = world.PerformBuyStock(c.playerID, payload.ItemID)
+		} else {
+			_, success = world.PerformBuyback(c.playerID, payload.ItemID)
+		}
+		if !success {
+			return
+		}
+		if player := world.GetEntityCopy(c.playerID); player != nil {
+			c.sendMessage(MsgInventory, player.Inventory)
+		}
+		if view, ok := world.GetMerchantView(c.playerID); ok {
+			c.sendMessage(MsgMerchant, view)
+		}
 
-	for _, client := range activeSessions {
-		if client.playerID == "" {
-			continue
+	case MsgSocket, MsgUnsocket:
+		if c.playerID == "" {
+			return
+		}
+		var payload SocketPayload
+		if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+			return
 		}
 
-		// Get custom state (60 unit radius)
-		state := world.GetStateForPlayer(client.playerID, 60.0)
-		payload, _ := json.Marshal(state)
+		var success bool
+		if msg.Type == MsgSocket {
+			_, success = world.PerformSocket(c.playerID, payload.ItemID, payload.GemID)
+		} else {
+			_, success = world.PerformUnsocket(c.playerID, payload.ItemID, payload.Socket)
+		}
+		if !success {
+			return
+		}
+		if player := world.GetEntityCopy(c.playerID); player != nil {
+			c.sendMessage(MsgInventory, player.Inventory)
+		}
 
-		msg := Message{
-			Type:    MsgState,
-			Payload: payload,
+	case MsgCombineGems:
+		if c.playerID == "" {
+			return
+		}
+		var payload CombineGemsPayload
+		if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+			return
 		}
-		data, _ := json.Marshal(msg)
 
-		// Non-blocking send
-		select {
-		case client.send <- data:
-		default:
-			// Drop message if client is too slow
+		if _, success := world.PerformCombineGems(c.playerID, payload.GemIDs); !success {
+			return
+		}
+		if player := world.GetEntityCopy(c.playerID); player != nil {
+			c.sendMessage(MsgInventory, player.Inventory)
 		}
-	}
-}
 
-func broadcastTime() {
-	// Send server time (seconds since epoch or just a counter)
-	// For game timer, maybe just send seconds elapsed since server start or a specific game time
-	// Let's send current Unix timestamp
-	now := time.Now().Unix()
-	payload, _ := json.Marshal(map[string]int64{"time": now})
-	msg := Message{
-		Type:    "time",
-		Payload: payload,
-	}
-	data, _ := json.Marshal(msg)
-	broadcast <- BroadcastMessage{Type: "time", Data: data}
-}
+	case MsgRepair:
+		if c.playerID == "" {
+			return
+		}
+		var payload RepairPayload
+		if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+			return
+		}
 
-func saveAllPlayers() {
-	// Create a snapshot of active sessions to avoid holding the lock during DB operations
-	var clientsToSave []*Client
-	sessionsMu.Lock()
-	for _, client := range activeSessions {
-		clientsToSave = append(clientsToSave, client)
-	}
-	sessionsMu.Unlock()
+		if _, success := world.PerformRepair(c.playerID, payload.ItemID); !success {
+			return
+		}
+		if player := world.GetEntityCopy(c.playerID); player != nil {
+			c.sendMessage(MsgInventory, player.Inventory)
+		}
 
-	for _, client := range clientsToSave {
-		savePlayer(client)
-	}
-}
+	case MsgSalvage:
+		if c.playerID == "" {
+			return
+		}
+		var payload SellPayload
+		if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+			return
+		}
 
-func savePlayer(client *Client) {
-	if client.playerID == "" || client.username == "" {
-		return
-	}
+		if _, success := world.PerformSalvage(c.playerID, payload.ItemID); !success {
+			return
+		}
+		if player := world.GetEntityCopy(c.playerID); player != nil {
+			c.sendMessage(MsgInventory, player.Inventory)
+			c.sendMessage(MsgMaterials, player.Materials)
+		}
 
-	entity := world.GetEntityCopy(client.playerID)
-	if entity == nil {
-		return
-	}
+	case MsgEnchant:
+		if c.playerID == "" {
+			return
+		}
+		var payload EnchantPayload
+		if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+			return
+		}
 
-	// Update DB character
-	char := &database.Character{
-		Name:  client.username,
-		Class: entity.SubType,
+		if _, success := world.PerformEnchant(c.playerID, payload.ItemID, payload.Affix); !success {
+			return
+		}
+		if player := world.GetEntityCopy(c.playerID); player != nil {
+			c.sendMessage(MsgInventory, player.Inventory)
+			c.sendMessage(MsgMaterials, player.Materials)
+		}
+
+	case MsgTalents:
+		if c.playerID == "" {
+			return
+		}
+		if player := world.GetEntityCopy(c.playerID); player != nil {
+			c.sendMessage(MsgTalents, talentsView(player))
+		}
+
+	case MsgAllocateStats, MsgLearnTalent, MsgRespec:
+		if c.playerID == "" {
+			return
+		}
+
+		var success bool
+		switch msg.Type {
+		case MsgAllocateStats:
+			var payload game.Stats
+			if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+				return
+			}
+			_, success = world.PerformAllocateStats(c.playerID, payload)
+		case MsgLearnTalent:
+			var payload TalentPayload
+			if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+				return
+			}
+			_, success = world.PerformLearnTalent(c.playerID, payload.TalentID)
+		case MsgRespec:
+			_, success = world.PerformRespec(c.playerID)
+		}
+		if !success {
+			return
+		}
+		if player := world.GetEntityCopy(c.playerID); player != nil {
+			c.sendMessage(MsgTalents, talentsView(player))
+		}
+
+	case MsgQuests:
+		if c.playerID == "" {
+			return
+		}
+		if questLog, ok := world.GetQuestLog(c.playerID); ok {
+			c.sendMessage(MsgQuests, questLog)
+		}
+
+	case MsgQuestAccept, MsgQuestAbandon, MsgQuestTurnIn:
+		if c.playerID == "" {
+			return
+		}
+		var payload QuestPayload
+		if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+			return
+		}
+
+		var success bool
+		switch msg.Type {
+		case MsgQuestAccept:
+			_, success = world.PerformQuestAccept(c.playerID, payload.QuestID)
+		case MsgQuestAbandon:
+			_, success = world.PerformQuestAbandon(c.playerID, payload.QuestID)
+		case MsgQuestTurnIn:
+			_, success = world.PerformQuestTurnIn(c.playerID, payload.QuestID)
+		}
+		if !success {
+			return
+		}
+		if msg.Type == MsgQuestTurnIn {
+			if player := world.GetEntityCopy(c.playerID); player != nil {
+				c.sendMessage(MsgInventory, player.Inventory)
+				c.sendMessage(MsgTalents, talentsView(player))
+			}
+		}
+		if questLog, ok := world.GetQuestLog(c.playerID); ok {
+			c.sendMessage(MsgQuests, questLog)
+		}
+
+	case MsgInteract:
+		if c.playerID == "" {
+			return
+		}
+		var payload InteractPayload
+		if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+			return
+		}
+		if view, ok := world.PerformInteract(c.playerID, payload.NPCID); ok {
+			c.sendMessage(MsgDialogue, view)
+		}
+
+	case MsgDialogueChoice:
+		if c.playerID == "" {
+			return
+		}
+		var payload DialogueChoicePayload
+		if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+			return
+		}
+		result, ok := world.PerformDialogueChoice(c.playerID, payload.NPCID, payload.NodeID, payload.Choice)
+		if !ok {
+			return
+		}
+		c.sendMessage(MsgDialogue, result.View)
+		if result.Teleport != nil {
+			c.sendMessage(MsgTeleport, result.Teleport)
+		}
+		if result.OpenShop {
+			if view, ok := world.GetMerchantView(c.playerID); ok {
+				c.sendMessage(MsgMerchant, view)
+			}
+		}
+		if result.Quests {
+			if player := world.GetEntityCopy(c.playerID); player != nil {
+				c.sendMessage(MsgInventory, player.Inventory)
+				c.sendMessage(MsgTalents, talentsView(player))
+			}
+			if questLog, ok := world.GetQuestLog(c.playerID); ok {
+				c.sendMessage(MsgQuests, questLog)
+			}
+		}
+
+	case MsgRegions:
+		if c.playerID == "" {
+			return
+		}
+		c.sendMessage(MsgRegions, world.GetRegions())
+
+	case MsgProfile:
+		if c.playerID == "" {
+			return
+		}
+		if profile, ok := world.GetProfile(c.playerID); ok {
+			c.sendMessage(MsgProfile, profile)
+		}
+
+	case MsgStash:
+		if c.playerID == "" {
+			return
+		}
+		player := world.GetEntityCopy(c.playerID)
+		if player == nil || player.Stash == nil {
+			return
+		}
+		c.sendMessage(MsgStash, player.Stash)
+
+	case MsgStashDeposit, MsgStashWithdraw:
+		if c.playerID == "" {
+			return
+		}
+		var payload StashPayload
+		if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+			return
+		}
+
+		var success bool
+		if msg.Type == MsgStashDeposit {
+			_, success = world.PerformStashDeposit(c.playerID, payload.ItemID, payload.Tab)
+		} else {
+			_, success = world.PerformStashWithdraw(c.playerID, payload.ItemID, payload.Tab)
+		}
+		if !success {
+			return
+		}
+
+		// Send both views so the client can redraw bag and stash together
+		player := world.GetEntityCopy(c.playerID)
+		if player == nil {
+			return
+		}
+		c.sendMessage(MsgInventory, player.Inventory)
+		c.sendMessage(MsgStash, player.Stash)
+
+	case MsgTradeRequest:
+		if c.playerID == "" {
+			return
+		}
+		var payload TradeTargetPayload
+		if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+			return
+		}
+		if !world.RequestTrade(c.playerID, payload.TargetID) {
+			c.sendError("Cannot trade with that player")
+			return
+		}
+		if target := clientForPlayer(payload.TargetID); target != nil {
+			target.sendMessage(MsgTradeRequest, TradeRequestNotice{FromID: c.playerID, FromName: c.username})
+		}
+
+	case MsgTradeAccept:
+		if c.playerID == "" {
+			return
+		}
+		var payload TradeTargetPayload
+		if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+			return
+		}
+		trade, success := world.AcceptTrade(c.playerID, payload.TargetID)
+		if !success {
+			c.sendError("Trade request expired")
+			return
+		}
+		notifyTrade(trade, MsgTrade, trade)
+
+	case MsgTradeOffer:
+		if c.playerID == "" {
+			return
+		}
+		var payload TradeOfferPayload
+		if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+			return
+		}
+		if trade, success := world.SetTradeOffer(c.playerID, payload.ItemIDs, payload.Gold); success {
+			notifyTrade(trade, MsgTrade, trade)
+		}
+
+	case MsgTradeLock:
+		if c.playerID == "" {
+			return
+		}
+		if trade, success := world.LockTrade(c.playerID); success {
+			notifyTrade(trade, MsgTrade, trade)
+		}
+
+	case MsgTradeConfirm:
+		if c.playerID == "" {
+			return
+		}
+		trade, completed, success := world.ConfirmTrade(c.playerID)
+		if trade == nil {
+			return
+		}
+		if !success {
+			// Validation failed at swap time; the trade has been closed
+			notifyTrade(trade, MsgTradeCancel, TradeCancelNotice{TradeID: trade.ID, Reason: "trade failed"})
+			return
+		}
+		if !completed {
+			notifyTrade(trade, MsgTrade, trade)
+			return
+		}
+
+		// Persist both sides immediately so a crash cannot duplicate or lose items
+		for _, id := range []string{trade.PlayerA, trade.PlayerB} {
+			client := clientForPlayer(id)
+			if client == nil {
+				continue
+			}
+			if player := world.GetEntityCopy(id); player != nil {
+				client.sendMessage(MsgInventory, player.Inventory)
+			}
+			client.sendMessage(MsgTradeComplete, trade)
+			if client == c {
+				savePlayer(c)
+			} else {
+				client.requestSave() // The partner saves on their own goroutine
+			}
+		}
+
+	case MsgTradeCancel:
+		if c.playerID == "" {
+			return
+		}
+		if trade, success := world.CancelTrade(c.playerID); success {
+			notifyTrade(trade, MsgTradeCancel, TradeCancelNotice{TradeID: trade.ID, Reason: "cancelled"})
+		}
+
+	case MsgAuctionList:
+		if c.playerID == "" {
+			return
+		}
+		var payload AuctionListPayload
+		if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+			return
+		}
+		duration, ok := game.AuctionDurations[payload.Hours]
+		if !ok {
+			c.sendError("Invalid listing duration")
+			return
+		}
+		count, err := db.CountActiveListings(c.username)
+		if err != nil || count >= game.MaxAuctionListings {
+			c.sendError("Too many active listings")
+			return
+		}
+
+		item, success := world.PerformAuctionList(c.playerID, payload.ItemID, payload.Price)
+		if !success {
+			return
+		}
+
+		now := time.Now()
+		listing := &database.Listing{
+			ID:        fmt.Sprintf("auction-%d", rand.Int63()),
+			Seller:    c.username,
+			Item:      toDBItem(*item),
+			Price:     payload.Price,
+			CreatedAt: now,
+			ExpiresAt: now.Add(duration),
+			Status:    database.ListingActive,
+		}
+		if err := db.CreateListing(listing); err != nil {
+			log.Printf("Failed to create listing for %s: %v", c.username, err)
+			// Put the item back where it came from
+			if !world.GiveItem(c.playerID, *item) {
+				db.DeliverItem(c.username, listing.Item)
+			}
+			c.sendError("Failed to create listing")
+		}
+		// Persist the removal so the item cannot exist in both places after a crash
+		savePlayer(c)
+		if player := world.GetEntityCopy(c.playerID); player != nil {
+			c.sendMessage(MsgInventory, player.Inventory)
+		}
+
+	case MsgAuctionSearch:
+		if c.playerID == "" {
+			return
+		}
+		var payload AuctionSearchPayload
+		if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+			return
+		}
+		listings, err := db.SearchListings(database.ListingFilter{
+			Slot:     payload.Slot,
+			Rarity:   payload.Rarity,
+			MinLevel: payload.MinLevel,
+			MaxLevel: payload.MaxLevel,
+		}, 50)
+		if err != nil {
+			log.Printf("Auction search failed: %v", err)
+			c.sendError("Auction house unavailable")
+			return
+		}
+		results := make([]AuctionListing, len(listings))
+		for i, l := range listings {
+			results[i] = AuctionListing{
+				ID:        l.ID,
+				Seller:    l.Seller,
+				Item:      toGameItem(l.Item),
+				Price:     l.Price,
+				ExpiresAt: l.ExpiresAt.Unix(),
+			}
+		}
+		c.sendMessage(MsgAuctionResults, results)
+
+	case MsgAuctionBuy:
+		if c.playerID == "" {
+			return
+		}
+		var payload AuctionListingPayload
+		if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+			return
+		}
+		listing, err := db.GetListing(payload.ListingID)
+		if err != nil || listing.Status != database.ListingActive {
+			c.sendError("Listing is no longer available")
+			return
+		}
+		if listing.Seller == c.username {
+			c.sendError("You cannot buy your own listing")
+			return
+		}
+
+		// Charge first, then claim; refund if someone else got there first
+		if !world.PerformAuctionBuy(c.playerID, listing.Price) {
+			return
+		}
+		claimed, err := db.ClaimListing(listing.ID, c.username, listing.Price)
+		if err != nil {
+			world.AddGold(c.playerID, listing.Price)
+			c.sendError("Listing is no longer available")
+			return
+		}
+
+		if !world.GiveItem(c.playerID, toGameItem(claimed.Item)) {
+			db.DeliverItem(c.username, claimed.Item)
+			deliverMailbox(c)
+		}
+		savePlayer(c)
+		if player := world.GetEntityCopy(c.playerID); player != nil {
+			c.sendMessage(MsgInventory, player.Inventory)
+		}
+
+		// Pay the seller through their mailbox (works whether they are online or not)
+		if err := db.DeliverGold(claimed.Seller, game.AuctionProceeds(claimed.Price)); err != nil {
+			log.Printf("Failed to deliver auction gold to %s: %v", claimed.Seller, err)
+		}
+		if seller := clientForUser(claimed.Seller); seller != nil {
+			seller.notifyMailbox()
+			seller.sendMessage(MsgAuctionSold, AuctionListing{
+				ID:     claimed.ID,
+				Seller: claimed.Seller,
+				Item:   toGameItem(claimed.Item),
+				Price:  claimed.Price,
+			})
+		}
+
+	case MsgAuctionCancel:
+		if c.playerID == "" {
+			return
+		}
+		var payload AuctionListingPayload
+		if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+			return
+		}
+		listing, err := db.CancelListing(payload.ListingID, c.username)
+		if err != nil {
+			c.sendError("Listing is no longer available")
+			return
+		}
+		db.DeliverItem(c.username, listing.Item)
+		deliverMailbox(c)
+
+	case MsgSocial:
+		// Gather online players
+		var playerList []SocialEntry
+		sessionsMu.Lock()
+		for _, client := range activeSessions {
+			if client.playerID != "" {
+				entity := world.GetEntity(client.playerID)
+				if entity != nil {
+					playerList = append(playerList, SocialEntry{
+						Name:  entity.Name,
+						Class: entity.SubType,
+						Level: entity.Level,
+					})
+				}
+			}
+		}
+		sessionsMu.Unlock()
+
+		payload, _ := json.Marshal(playerList)
+		msg := Message{
+			Type:    MsgSocial,
+			Payload: payload,
+		}
+		b, _ := json.Marshal(msg)
+		c.send <- b
+	}
+}
+
+// sendMessage wraps a payload in a Message envelope and queues it for this client.
+func (c *Client) sendMessage(msgType string, payload interface{}) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Recovered from sendMessage panic: %v", r)
+		}
+	}()
+	b, _ := json.Marshal(payload)
+	m := Message{
+		Type:    msgType,
+		Payload: b,
+	}
+	data, _ := json.Marshal(m)
+	c.send <- data
+}
+
+// clientForPlayer finds the connected client controlling a player entity.
+func clientForPlayer(playerID string) *Client {
+	sessionsMu.Lock()
+	defer sessionsMu.Unlock()
+	for _, client := range activeSessions {
+		if client.playerID == playerID {
+			return client
+		}
+	}
+	return nil
+}
+
+func clientForUser(username string) *Client {
+	sessionsMu.Lock()
+	defer sessionsMu.Unlock()
+	return activeSessions[username]
+}
+
+// deliverMailbox moves gold and items waiting in the database mailbox onto the
+// player's character. Items that do not fit are posted back for next time.
+func deliverMailbox(c *Client) {
+	if c.playerID == "" || c.username == "" {
+		return
+	}
+	gold, items, err := db.ClaimMailbox(c.username)
+	if err != nil {
+		log.Printf("Failed to claim mailbox for %s: %v", c.username, err)
+		return
+	}
+	if gold == 0 && len(items) == 0 {
+		return
+	}
+
+	// Mailbox gold is auction sale proceeds
+	if gold > 0 && !world.EarnGold(c.playerID, gold) {
+		db.DeliverGold(c.username, gold)
+	}
+	for _, dbItem := range items {
+		if !world.GiveItem(c.playerID, toGameItem(dbItem)) {
+			db.DeliverItem(c.username, dbItem)
+		}
+	}
+	savePlayer(c)
+
+	if player := world.GetEntityCopy(c.playerID); player != nil {
+		c.sendMessage(MsgInventory, player.Inventory)
+	}
+}
+
+// sweepAuctions returns expired listings to their sellers.
+func sweepAuctions() {
+	expired, err := db.ExpireListings(time.Now())
+	if err != nil {
+		log.Printf("Auction sweep failed: %v", err)
+	}
+	for _, listing := range expired {
+		if err := db.DeliverItem(listing.Seller, listing.Item); err != nil {
+			log.Printf("Failed to return expired listing %s to %s: %v", listing.ID, listing.Seller, err)
+			continue
+		}
+		if seller := clientForUser(listing.Seller); seller != nil {
+			seller.notifyMailbox()
+		}
+	}
+}
+
+// notifyPlayer sends a message to a player if they are online.
+func notifyPlayer(playerID, msgType string, payload interface{}) {
+	if client := clientForPlayer(playerID); client != nil {
+		client.sendMessage(msgType, payload)
+	}
+}
+
+// notifyTrade sends the same message to both participants of a trade.
+func notifyTrade(trade *game.Trade, msgType string, payload interface{}) {
+	for _, id := range []string{trade.PlayerA, trade.PlayerB} {
+		if client := clientForPlayer(id); client != nil {
+			client.sendMessage(msgType, payload)
+		}
+	}
+}
+
+func (c *Client) sendError(msg string) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Recovered from sendError panic: %v", r)
+		}
+	}()
+	m := Message{
+		Type:    MsgError,
+		Payload: json.RawMessage(`"` + msg + `"`),
+	}
+	b, _ := json.Marshal(m)
+	c.send <- b
+}
+
+func broadcastState() {
+	// Iterate over active sessions and send custom state to each
+	sessionsMu.Lock()
+	defer sessionsMu.Unlock()
+
+	for _, client := range activeSessions {
+		if client.playerID == "" {
+			continue
+		}
+
+		// Get custom state (60 unit radius)
+		state := world.GetStateForPlayer(client.playerID, viewDistance)
+		payload, _ := json.Marshal(state)
+
+		msg := Message{
+			Type:    MsgState,
+			Payload: payload,
+		}
+		data, _ := json.Marshal(msg)
+
+		// Non-blocking send
+		select {
+		case client.send <- data:
+		default:
+			// Drop message if client is too slow
+		}
+	}
+}
+
+// broadcastCombatEvents sends each player the events they can see this tick.
+func broadcastCombatEvents(events []game.CombatEvent) {
+	if len(events) == 0 {
+		return
+	}
+
+	sessionsMu.Lock()
+	defer sessionsMu.Unlock()
+
+	for _, client := range activeSessions {
+		if client.playerID == "" {
+			continue
+		}
+		visible := world.EventsForPlayer(events, client.playerID, viewDistance)
+		if len(visible) == 0 {
+			continue
+		}
+
+		payload, _ := json.Marshal(visible)
+		msg := Message{
+			Type:    MsgCombat,
+			Payload: payload,
+		}
+		data, _ := json.Marshal(msg)
+
+		// Non-blocking send
+		select {
+		case client.send <- data:
+		default:
+			// Drop message if client is too slow
+		}
+	}
+}
+
+func broadcastTime() {
+	// Send server time (seconds since epoch or just a counter)
+	// For game timer, maybe just send seconds elapsed since server start or a specific game time
+	// Let's send current Unix timestamp
+	now := time.Now().Unix()
+	payload, _ := json.Marshal(map[string]int64{"time": now})
+	msg := Message{
+		Type:    "time",
+		Payload: payload,
+	}
+	data, _ := json.Marshal(msg)
+	broadcast <- BroadcastMessage{Type: "time", Data: data}
+}
+
+func saveAllPlayers() {
+	// Create a snapshot of active sessions to avoid holding the lock during DB operations
+	var clientsToSave []*Client
+	sessionsMu.Lock()
+	for _, client := range activeSessions {
+		clientsToSave = append(clientsToSave, client)
+	}
+	sessionsMu.Unlock()
+
+	for _, client := range clientsToSave {
+		savePlayer(client)
+	}
+}
+
+func savePlayer(client *Client) {
+	if client.playerID == "" || client.username == "" {
+		return
+	}
+
+	entity := world.GetEntityCopy(client.playerID)
+	if entity == nil {
+		return
+	}
+
+	// Update DB character
+	char := &database.Character{
+		Name:  client.username,
+		Class: entity.SubType,
 		Level: entity.Level,
 		XP:    entity.Experience,
 		Gold:  entity.Gold,
 		X:     entity.X,
 		Y:     entity.Y,
 		Z:     entity.Z,
+
+		LootPity:     entity.LootPity,
+		Materials:    entity.Materials,
+		StatPoints:   entity.StatPoints,
+		TalentPoints: entity.TalentPoints,
+		Talents:      entity.Talents,
+		AllocatedStats: database.Stats{
+			Vitality:     entity.AllocatedStats.Vitality,
+			Strength:     entity.AllocatedStats.Strength,
+			Dexterity:    entity.AllocatedStats.Dexterity,
+			Intelligence: entity.AllocatedStats.Intelligence,
+			Wisdom:       entity.AllocatedStats.Wisdom,
+		},
 		Stats: database.Stats{
 			Vitality:     entity.BaseStats.Vitality,
 			Strength:     entity.BaseStats.Strength,
@@ -898,22 +1785,21 @@ func savePlayer(client *Client) {
 		},
 	}
 
+	// Quests
+	for _, q := range entity.Quests {
+		char.Quests = append(char.Quests, database.QuestProgress{QuestID: q.QuestID, Progress: q.Progress, Complete: q.Complete})
+	}
+	for id, done := range entity.CompletedQuests {
+		if done {
+			char.CompletedQuests = append(char.CompletedQuests, id)
+		}
+	}
+
 	// Convert Game Inventory to DB Inventory
 	if len(entity.Inventory) > 0 {
 		char.Inventory = make([]database.Item, len(entity.Inventory))
 		for i, item := range entity.Inventory {
-			char.Inventory[i] = database.Item{
-				ID:          item.ID,
-				Name:        item.Name,
-				Type:        string(item.Type),
-				Rarity:      string(item.Rarity),
-				Slot:        item.Slot,
-				Level:       item.Level,
-				Value:       item.Value,
-				Icon:        item.Icon,
-				Description: item.Description,
-				Stats:       item.Stats,
-			}
+			char.Inventory[i] = toDBItem(item)
 		}
 	}
 
@@ -921,18 +1807,7 @@ func savePlayer(client *Client) {
 	if len(entity.Equipment) > 0 {
 		char.Equipment = make(map[string]database.Item)
 		for slot, item := range entity.Equipment {
-			char.Equipment[slot] = database.Item{
-				ID:          item.ID,
-				Name:        item.Name,
-				Type:        string(item.Type),
-				Rarity:      string(item.Rarity),
-				Slot:        item.Slot,
-				Level:       item.Level,
-				Value:       item.Value,
-				Icon:        item.Icon,
-				Description: item.Description,
-				Stats:       item.Stats,
-			}
+			char.Equipment[slot] = toDBItem(item)
 		}
 	}
 
@@ -944,4 +1819,159 @@ func savePlayer(client *Client) {
 		log.Printf("Saved character for %s (Inv: %d, Equip: %d)", client.username, len(char.Inventory), len(char.Equipment))
 	}
 	// }(client.username, char)
+
+	if entity.Stash != nil {
+		if err := db.SaveStash(client.username, toDBStash(entity.Stash)); err != nil {
+			log.Printf("Failed to save stash for %s: %v", client.username, err)
+		}
+	}
+
+	if entity.Profile != nil {
+		if err := db.SaveProfile(client.username, toDBProfile(entity.Profile)); err != nil {
+			log.Printf("Failed to save profile for %s: %v", client.username, err)
+		}
+	}
+}
+
+func talentsView(player *game.Entity) TalentsPayload {
+	return TalentsPayload{
+		StatPoints:   player.StatPoints,
+		TalentPoints: player.TalentPoints,
+		Talents:      player.Talents,
+		RespecCost:   game.RespecCost(player.Level),
+	}
+}
+
+func toGameItem(dbItem database.Item) game.Item {
+	item := game.Item{
+		ID:          dbItem.ID,
+		Name:        dbItem.Name,
+		Type:        game.ItemType(dbItem.Type),
+		Rarity:      game.ItemRarity(dbItem.Rarity),
+		Slot:        dbItem.Slot,
+		Level:       dbItem.Level,
+		Value:       dbItem.Value,
+		Icon:        dbItem.Icon,
+		Description: dbItem.Description,
+		Stats:       dbItem.Stats,
+		Base:        dbItem.Base,
+		Implicit:    dbItem.Implicit,
+		UniqueID:    dbItem.UniqueID,
+		SetID:       dbItem.SetID,
+		Sockets:     dbItem.Sockets,
+		GemType:     dbItem.GemType,
+		GemTier:     dbItem.GemTier,
+
+		Durability:    dbItem.Durability,
+		MaxDurability: dbItem.MaxDurability,
+	}
+	for _, a := range dbItem.Affixes {
+		item.Affixes = append(item.Affixes, game.ItemAffix{
+			ID:    a.ID,
+			Kind:  game.AffixKind(a.Kind),
+			Stat:  a.Stat,
+			Tier:  a.Tier,
+			Value: a.Value,
+
+			Enchanted: a.Enchanted,
+		})
+	}
+	for _, gem := range dbItem.Gems {
+		item.Gems = append(item.Gems, toGameItem(gem))
+	}
+	return item
+}
+
+func toDBItem(item game.Item) database.Item {
+	dbItem := database.Item{
+		ID:          item.ID,
+		Name:        item.Name,
+		Type:        string(item.Type),
+		Rarity:      string(item.Rarity),
+		Slot:        item.Slot,
+		Level:       item.Level,
+		Value:       item.Value,
+		Icon:        item.Icon,
+		Description: item.Description,
+		Stats:       item.Stats,
+		Base:        item.Base,
+		Implicit:    item.Implicit,
+		UniqueID:    item.UniqueID,
+		SetID:       item.SetID,
+		Sockets:     item.Sockets,
+		GemType:     item.GemType,
+		GemTier:     item.GemTier,
+
+		Durability:    item.Durability,
+		MaxDurability: item.MaxDurability,
+	}
+	for _, a := range item.Affixes {
+		dbItem.Affixes = append(dbItem.Affixes, database.ItemAffix{
+			ID:    a.ID,
+			Kind:  string(a.Kind),
+			Stat:  a.Stat,
+			Tier:  a.Tier,
+			Value: a.Value,
+
+			Enchanted: a.Enchanted,
+		})
+	}
+	for _, gem := range item.Gems {
+		dbItem.Gems = append(dbItem.Gems, toDBItem(gem))
+	}
+	return dbItem
+}
+
+func toGameStash(dbStash *database.Stash) *game.Stash {
+	stash := game.NewStash()
+	if dbStash == nil {
+		return stash
+	}
+	for i, tab := range dbStash.Tabs {
+		if i >= len(stash.Tabs) {
+			break
+		}
+		for _, dbItem := range tab {
+			stash.Tabs[i] = append(stash.Tabs[i], toGameItem(dbItem))
+		}
+	}
+	return stash
+}
+
+func toDBStash(stash *game.Stash) *database.Stash {
+	dbStash := &database.Stash{Tabs: make([][]database.Item, len(stash.Tabs))}
+	for i, tab := range stash.Tabs {
+		dbStash.Tabs[i] = make([]database.Item, len(tab))
+		for j, item := range tab {
+			dbStash.Tabs[i][j] = toDBItem(item)
+		}
+	}
+	return dbStash
+}
+
+func toGameProfile(dbProfile *database.Profile) *game.Profile {
+	profile := game.NewProfile()
+	if dbProfile == nil {
+		return profile
+	}
+	for id, at := range dbProfile.Achievements {
+		profile.Achievements[id] = at
+	}
+	for subType, n := range dbProfile.Kills {
+		profile.Stats.Kills[subType] = n
+	}
+	profile.Stats.Deaths = dbProfile.Deaths
+	profile.Stats.GoldEarned = dbProfile.GoldEarned
+	profile.Stats.DamageDealt = dbProfile.DamageDealt
+	return profile
+}
+
+func toDBProfile(profile *game.Profile) *database.Profile {
+	return &database.Profile{
+		Achievements: profile.Achievements,
+		Kills:        profile.Stats.Kills,
+		Deaths:       profile.Stats.Deaths,
+		GoldEarned:   profile.Stats.GoldEarned,
+		DamageDealt:  profile.Stats.DamageDealt,
+	}
 }
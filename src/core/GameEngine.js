@@ -105,6 +105,7 @@ export class GameEngine {
         this.needsRaycast = false;
         this.activeEntitiesCache = [];
         this.frameCount = 0;
+        this.teleportSeq = 0; // Latest server teleport applied; sent with move updates
 
         // Entity Creation Throttling
         this.entityCreationQueue = [];
@@ -606,6 +607,17 @@ export class GameEngine {
             if (this.player && dmgData.targetId === this.player.id) {
                 // this.renderSystem.shakeCamera(0.2);
             }
+        } else if (msg.type === 'teleport') {
+            // Server moved us (e.g. an NPC escort); moves are ignored until we acknowledge it
+            const tp = msg.payload;
+            this.player.position.set(tp.x, 0, tp.z);
+            this.player.targetPosition = null;
+            this.player.state = 'IDLE';
+            this.teleportSeq = tp.seq;
+
+            this.chunkManager.updateEntityChunk(this.player);
+            this.renderSystem.setCameraTarget(this.player.position);
+            this.chunkManager.update(this.player, 0, this.collisionManager);
         } else if (msg.type === 'error') {
             console.error("Server Error:", msg.payload);
             alert(`Server Error: ${msg.payload}`);
@@ -1612,7 +1624,8 @@ export class GameEngine {
                         y: this.player.position.y,
                         z: this.player.position.z,
                         rotation: euler.y,
-                        state: this.player.state
+                        state: this.player.state,
+                        teleport: this.teleportSeq
                     }
                 };
                 this.socket.send(JSON.stringify(moveMsg));